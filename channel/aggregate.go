@@ -0,0 +1,116 @@
+package channel
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// defaultWindow is the window of an Aggregator given a non positive one
+const defaultWindow = time.Second
+
+// Summary is what an Aggregator emits for each key at the end of a window
+type Summary[K comparable, R any] struct {
+	Key   K
+	Value R
+}
+
+// Aggregator folds the values passing the middlewares per key over tumbling windows,
+// and emits one Summary per key when the window closes or when the aggregator is closed.
+// Each window starts from the zero value of R, a window that is not positive lasts one second.
+type Aggregator[T any, K comparable, R any] struct {
+	mu      sync.Mutex
+	key     func(T) K
+	fold    func(R, T) R
+	acc     map[K]R
+	out     chan Summary[K, R]
+	quit    chan struct{}
+	done    chan struct{}
+	closed  bool
+	options options[T]
+}
+
+func NewAggregator[T any, K comparable, R any](key func(T) K, window time.Duration, fold func(R, T) R, params ...WithOptions[T]) *Aggregator[T, K, R] {
+	opts := newOptions(params)
+	if window <= 0 {
+		window = defaultWindow
+	}
+
+	ticker := opts.clock.NewTicker(window)
+
+	a := &Aggregator[T, K, R]{
+		key:     key,
+		fold:    fold,
+		acc:     map[K]R{},
+		out:     make(chan Summary[K, R]),
+		quit:    make(chan struct{}),
+		done:    make(chan struct{}),
+		options: opts,
+	}
+
+	go func() {
+		defer close(a.done)
+		defer close(a.out)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C():
+				a.flush()
+			case <-a.quit:
+				a.flush()
+				return
+			}
+		}
+	}()
+
+	return a
+}
+
+func (a *Aggregator[T, K, R]) Out() <-chan Summary[K, R] {
+	return a.out
+}
+
+// Send runs the middlewares on data and folds it into the current window of its key,
+// values sent after Close are ignored
+func (a *Aggregator[T, K, R]) Send(data T) {
+	_ = a.options.run(context.Background(), data, a.add)
+}
+
+func (a *Aggregator[T, K, R]) add(data T) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.closed {
+		return
+	}
+
+	key := a.key(data)
+	a.acc[key] = a.fold(a.acc[key], data)
+}
+
+// Close emits the current window and closes Out once it is consumed
+func (a *Aggregator[T, K, R]) Close() {
+	a.mu.Lock()
+	if a.closed {
+		a.mu.Unlock()
+		return
+	}
+
+	a.closed = true
+	a.mu.Unlock()
+
+	close(a.quit)
+	<-a.done
+}
+
+func (a *Aggregator[T, K, R]) flush() {
+	a.mu.Lock()
+	acc := a.acc
+	a.acc = make(map[K]R, len(acc))
+	a.mu.Unlock()
+
+	for key, value := range acc {
+		a.out <- Summary[K, R]{Key: key, Value: value}
+	}
+}
@@ -0,0 +1,100 @@
+package channel_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/lamlv2305/rok/channel"
+	"github.com/lamlv2305/rok/channel/fakeclock"
+)
+
+type sale struct {
+	region string
+	amount int
+}
+
+func TestAggregator(t *testing.T) {
+	clock := fakeclock.New(time.Unix(0, 0))
+	a := channel.NewAggregator(
+		func(s sale) string { return s.region },
+		time.Minute,
+		func(count int, _ sale) int { return count + 1 },
+		channel.WithClock[sale](clock),
+		channel.WithMiddleware(func(s sale) bool { return s.amount > 0 }),
+	)
+
+	window := func(n int) map[string]int {
+		t.Helper()
+
+		got := map[string]int{}
+		for range n {
+			select {
+			case summary, ok := <-a.Out():
+				if !ok {
+					t.Fatal("Out closed early")
+				}
+
+				got[summary.Key] = summary.Value
+			case <-time.After(time.Second):
+				t.Fatalf("got %v, want %d summaries", got, n)
+			}
+		}
+
+		return got
+	}
+
+	for _, s := range []sale{{"eu", 1}, {"us", 5}, {"eu", 2}, {"eu", 0}} {
+		a.Send(s)
+	}
+
+	select {
+	case summary := <-a.Out():
+		t.Fatalf("emitted %v before the window closed", summary)
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	clock.Advance(time.Minute)
+	if got := window(2); got["eu"] != 2 || got["us"] != 1 {
+		t.Fatalf("first window %v, want eu 2 and us 1", got)
+	}
+
+	// the next window starts empty and is emitted on Close
+	a.Send(sale{"us", 3})
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		a.Close()
+	}()
+
+	if got := window(1); len(got) != 1 || got["us"] != 1 {
+		t.Fatalf("last window %v, want us 1", got)
+	}
+
+	<-closed
+	if _, ok := <-a.Out(); ok {
+		t.Fatal("Out still open after Close")
+	}
+}
+
+func TestAggregatorDefaultWindow(t *testing.T) {
+	clock := fakeclock.New(time.Unix(0, 0))
+	a := channel.NewAggregator(
+		func(s sale) string { return s.region },
+		0,
+		func(total int, s sale) int { return total + s.amount },
+		channel.WithClock[sale](clock),
+	)
+	defer a.Close()
+
+	a.Send(sale{"eu", 4})
+	clock.Advance(time.Second)
+
+	select {
+	case summary := <-a.Out():
+		if summary.Key != "eu" || summary.Value != 4 {
+			t.Fatalf("emitted %v, want eu 4", summary)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("a zero window did not close after one second")
+	}
+}
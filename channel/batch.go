@@ -0,0 +1,108 @@
+package channel
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Batcher groups the values passing the middlewares and hands them to flush
+// once maxSize values are collected or maxWait elapsed since the first value of the batch.
+// flush is never called concurrently and receives batches in order.
+type Batcher[T any] struct {
+	mu      sync.Mutex
+	maxSize int
+	maxWait time.Duration
+	flush   func([]T)
+	options options[T]
+
+	batch  []T
+	timer  Timer
+	gen    uint64
+	closed bool
+}
+
+func NewBatcher[T any](maxSize int, maxWait time.Duration, flush func([]T), params ...WithOptions[T]) *Batcher[T] {
+	if maxSize < 1 {
+		maxSize = 1
+	}
+
+	return &Batcher[T]{
+		maxSize: maxSize,
+		maxWait: maxWait,
+		flush:   flush,
+		options: newOptions(params),
+	}
+}
+
+// Send runs the middlewares on data and adds it to the current batch,
+// values sent after Close are ignored
+func (b *Batcher[T]) Send(data T) {
+	_ = b.options.run(context.Background(), data, b.add)
+}
+
+func (b *Batcher[T]) add(data T) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return
+	}
+
+	b.batch = append(b.batch, data)
+	if len(b.batch) >= b.maxSize {
+		b.flushLocked()
+		return
+	}
+
+	if len(b.batch) == 1 && b.maxWait > 0 {
+		gen := b.gen
+		b.timer = b.options.clock.AfterFunc(b.maxWait, func() {
+			b.mu.Lock()
+			defer b.mu.Unlock()
+
+			// the batch was already flushed by size or by Close
+			if gen == b.gen {
+				b.flushLocked()
+			}
+		})
+	}
+}
+
+// Consume sends every value of in, then closes the batcher once in is closed
+func (b *Batcher[T]) Consume(in <-chan T) {
+	for data := range in {
+		b.Send(data)
+	}
+
+	b.Close()
+}
+
+// Close flushes the partial batch, if any
+func (b *Batcher[T]) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return
+	}
+
+	b.closed = true
+	b.flushLocked()
+}
+
+func (b *Batcher[T]) flushLocked() {
+	if b.timer != nil {
+		b.timer.Stop()
+		b.timer = nil
+	}
+
+	b.gen++
+	if len(b.batch) == 0 {
+		return
+	}
+
+	batch := b.batch
+	b.batch = make([]T, 0, b.maxSize)
+	b.flush(batch)
+}
@@ -0,0 +1,65 @@
+package channel_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/lamlv2305/rok/channel"
+	"github.com/lamlv2305/rok/channel/fakeclock"
+)
+
+func TestBatcherSize(t *testing.T) {
+	var sizes []int
+	next := 0
+	b := channel.NewBatcher(100, time.Hour, func(batch []int) {
+		for _, v := range batch {
+			if v != next {
+				t.Fatalf("got %d, want %d: batches out of order", v, next)
+			}
+			next++
+		}
+
+		sizes = append(sizes, len(batch))
+	})
+
+	for idx := range 250 {
+		b.Send(idx)
+	}
+
+	b.Close()
+	if len(sizes) != 3 || sizes[0] != 100 || sizes[1] != 100 || sizes[2] != 50 {
+		t.Fatalf("flushed batches of %v, want [100 100 50]", sizes)
+	}
+}
+
+func TestBatcherWait(t *testing.T) {
+	clock := fakeclock.New(time.Unix(0, 0))
+	flushed := make(chan []int, 4)
+	b := channel.NewBatcher(100, time.Second, func(batch []int) { flushed <- batch },
+		channel.WithClock[int](clock),
+		// middlewares run per item before batching
+		channel.WithMiddleware(func(v int) bool { return v%2 == 0 }),
+	)
+	defer b.Close()
+
+	for idx := range 6 {
+		b.Send(idx)
+	}
+
+	clock.Advance(time.Second - time.Nanosecond)
+	select {
+	case batch := <-flushed:
+		t.Fatalf("flushed %v before maxWait", batch)
+	default:
+	}
+
+	clock.Advance(time.Nanosecond)
+	select {
+	case batch := <-flushed:
+		if len(batch) != 3 || batch[0] != 0 || batch[1] != 2 || batch[2] != 4 {
+			t.Fatalf("flushed %v, want [0 2 4]", batch)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("nothing flushed once maxWait elapsed")
+	}
+}
@@ -0,0 +1,214 @@
+package channel
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type BreakerState int
+
+const (
+	// BreakerClosed lets every call through
+	BreakerClosed BreakerState = iota
+	// BreakerOpen fails every call with ErrCircuitOpen until the cooldown elapsed
+	BreakerOpen
+	// BreakerHalfOpen lets a single trial call through, its outcome closes or reopens the breaker
+	BreakerHalfOpen
+)
+
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerClosed:
+		return "closed"
+	case BreakerOpen:
+		return "open"
+	case BreakerHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// BreakerOpts configures a Breaker, zero fields take the default mentioned on each of them
+type BreakerOpts struct {
+	// Window is how many of the last calls the error ratio is computed over, 20 by default
+	Window int
+	// MinCalls is how many calls the window needs before the breaker can trip, Window by default
+	MinCalls int
+	// ErrorRatio trips the breaker once that fraction of the window failed, 0.5 by default
+	ErrorRatio float64
+	// Cooldown is how long the breaker stays open before a trial call, 5s by default,
+	// timed with the clock of WithClock
+	Cooldown time.Duration
+}
+
+// Breaker is a stage failing fast with ErrCircuitOpen while the stage it wraps keeps failing.
+// The same Breaker must be shared by every caller of one downstream, it is safe for concurrent use.
+type Breaker[T any] struct {
+	mu    sync.Mutex
+	stage Stage[T]
+	opts  BreakerOpts
+	clock *clockRef
+
+	state BreakerState
+	// outcomes of the last calls while closed, true for a failure
+	window   []bool
+	next     int
+	failures int
+	openedAt time.Time
+	// a trial call is in flight while half-open
+	probing bool
+	// bumped on every open and reset, a call only records its outcome in the window
+	// it started in, so a slow call from before a transition cannot affect the next state
+	gen uint64
+}
+
+// CircuitBreaker wraps stage in a new Breaker, use NewBreaker to also read its state
+func CircuitBreaker[T any](stage Stage[T], opts BreakerOpts) Stage[T] {
+	return NewBreaker(stage, opts)
+}
+
+// NewBreaker wraps stage, which counts as failed whenever it rejects a value. Splitters are not supported.
+func NewBreaker[T any](stage Stage[T], opts BreakerOpts) *Breaker[T] {
+	if opts.Window < 1 {
+		opts.Window = 20
+	}
+
+	if opts.MinCalls < 1 || opts.MinCalls > opts.Window {
+		opts.MinCalls = opts.Window
+	}
+
+	if opts.ErrorRatio <= 0 {
+		opts.ErrorRatio = 0.5
+	}
+
+	if opts.Cooldown <= 0 {
+		opts.Cooldown = 5 * time.Second
+	}
+
+	return &Breaker[T]{
+		stage:  stage,
+		opts:   opts,
+		clock:  newClockRef(),
+		window: make([]bool, 0, opts.Window),
+	}
+}
+
+func (b *Breaker[T]) step() step[T] {
+	inner := b.stage.step()
+	if inner.split != nil {
+		panic("channel: Breaker does not support splitters")
+	}
+
+	return step[T]{
+		name: inner.name,
+		fn: func(ctx context.Context, data T) (T, error) {
+			return b.call(ctx, inner.fn, data)
+		},
+		clock: func(c Clock) {
+			b.clock.set(c)
+			bindAll(inner)(c)
+		},
+	}
+}
+
+// State is the current state, an open breaker whose cooldown elapsed reports BreakerHalfOpen
+func (b *Breaker[T]) State() BreakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.cool()
+	return b.state
+}
+
+// call runs fn, the wrapped stage, unless the breaker is open
+func (b *Breaker[T]) call(ctx context.Context, fn func(context.Context, T) (T, error), data T) (T, error) {
+	b.mu.Lock()
+	b.cool()
+	gen, probe := b.gen, false
+	switch b.state {
+	case BreakerOpen:
+		b.mu.Unlock()
+		return data, ErrCircuitOpen
+	case BreakerHalfOpen:
+		if b.probing {
+			b.mu.Unlock()
+			return data, ErrCircuitOpen
+		}
+
+		b.probing, probe = true, true
+	}
+	b.mu.Unlock()
+
+	// a panic counts as a failure, otherwise a half-open breaker would wait for its trial forever
+	failed := true
+	defer func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		b.record(gen, probe, failed)
+	}()
+
+	result, err := fn(ctx, data)
+	failed = err != nil
+	return result, err
+}
+
+// cool and record must be called with mu held
+func (b *Breaker[T]) cool() {
+	if b.state == BreakerOpen && b.clock.get().Now().Sub(b.openedAt) >= b.opts.Cooldown {
+		b.state = BreakerHalfOpen
+	}
+}
+
+func (b *Breaker[T]) record(gen uint64, probe, failed bool) {
+	// only the trial resolves a half-open breaker, whatever else is still in flight
+	if probe {
+		b.probing = false
+		if failed {
+			b.open()
+		} else {
+			b.reset()
+		}
+
+		return
+	}
+
+	// a call started before the breaker last opened or closed has nothing left to say
+	if gen != b.gen || b.state != BreakerClosed {
+		return
+	}
+
+	if len(b.window) < b.opts.Window {
+		b.window = append(b.window, failed)
+	} else {
+		if b.window[b.next] {
+			b.failures--
+		}
+
+		b.window[b.next] = failed
+		b.next = (b.next + 1) % b.opts.Window
+	}
+
+	if failed {
+		b.failures++
+	}
+
+	if len(b.window) >= b.opts.MinCalls && float64(b.failures) >= b.opts.ErrorRatio*float64(len(b.window)) {
+		b.open()
+	}
+}
+
+func (b *Breaker[T]) open() {
+	b.state = BreakerOpen
+	b.gen++
+	b.openedAt = b.clock.get().Now()
+}
+
+func (b *Breaker[T]) reset() {
+	b.state = BreakerClosed
+	b.gen++
+	b.window = b.window[:0]
+	b.next = 0
+	b.failures = 0
+}
@@ -0,0 +1,143 @@
+package channel_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/lamlv2305/rok/channel"
+	"github.com/lamlv2305/rok/channel/fakeclock"
+)
+
+var errDownstream = errors.New("downstream failed")
+
+// call tells the stage wrapped by the breaker how to behave
+type call struct {
+	fail bool
+	// closed by the stage once called when set
+	entered chan struct{}
+	// the stage waits for it to be closed when set
+	gate chan struct{}
+}
+
+func gated() call {
+	return call{entered: make(chan struct{}), gate: make(chan struct{})}
+}
+
+func newBreaker(t *testing.T) (*channel.Channel[call], *channel.Breaker[call], *fakeclock.Clock) {
+	t.Helper()
+
+	clock := fakeclock.New(time.Unix(0, 0))
+	breaker := channel.NewBreaker(channel.ErrMiddleware[call](func(c call) error {
+		if c.entered != nil {
+			close(c.entered)
+		}
+
+		if c.gate != nil {
+			<-c.gate
+		}
+
+		if c.fail {
+			return errDownstream
+		}
+
+		return nil
+	}), channel.BreakerOpts{Window: 4, ErrorRatio: 0.5, Cooldown: time.Second})
+
+	c, err := channel.New(64, channel.WithClock[call](clock), channel.WithStage[call](breaker))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return c, breaker, clock
+}
+
+func trip(t *testing.T, c *channel.Channel[call]) {
+	t.Helper()
+
+	for range 4 {
+		if err := c.Send(call{fail: true}); !errors.Is(err, errDownstream) {
+			t.Fatalf("Send returned %v, want the downstream error", err)
+		}
+	}
+}
+
+func wantState(t *testing.T, b *channel.Breaker[call], want channel.BreakerState) {
+	t.Helper()
+
+	if got := b.State(); got != want {
+		t.Fatalf("state %v, want %v", got, want)
+	}
+}
+
+func TestBreakerTransitions(t *testing.T) {
+	c, breaker, clock := newBreaker(t)
+	wantState(t, breaker, channel.BreakerClosed)
+
+	trip(t, c)
+	wantState(t, breaker, channel.BreakerOpen)
+	if err := c.Send(call{}); !errors.Is(err, channel.ErrCircuitOpen) {
+		t.Fatalf("Send while open returned %v, want ErrCircuitOpen", err)
+	}
+
+	clock.Advance(time.Second)
+	wantState(t, breaker, channel.BreakerHalfOpen)
+	if err := c.Send(call{fail: true}); !errors.Is(err, errDownstream) {
+		t.Fatalf("failed trial returned %v, want the downstream error", err)
+	}
+
+	wantState(t, breaker, channel.BreakerOpen)
+
+	clock.Advance(time.Second)
+	if err := c.Send(call{}); err != nil {
+		t.Fatalf("trial returned %v", err)
+	}
+
+	wantState(t, breaker, channel.BreakerClosed)
+	if err := c.Send(call{fail: true}); !errors.Is(err, errDownstream) {
+		t.Fatalf("a single failure after closing returned %v, want the downstream error", err)
+	}
+
+	wantState(t, breaker, channel.BreakerClosed)
+}
+
+func TestBreakerStaleCallDoesNotResolveTrial(t *testing.T) {
+	c, breaker, clock := newBreaker(t)
+
+	// started while closed, finishes while the trial is in flight
+	stale := gated()
+	staleDone := make(chan error, 1)
+	go func() {
+		staleDone <- c.Send(stale)
+	}()
+
+	<-stale.entered
+
+	trip(t, c)
+	clock.Advance(time.Second)
+
+	probe := gated()
+	probeDone := make(chan error, 1)
+	go func() {
+		probeDone <- c.Send(probe)
+	}()
+
+	<-probe.entered
+
+	close(stale.gate)
+	if err := <-staleDone; err != nil {
+		t.Fatalf("stale call returned %v", err)
+	}
+
+	wantState(t, breaker, channel.BreakerHalfOpen)
+	if err := c.Send(call{}); !errors.Is(err, channel.ErrCircuitOpen) {
+		t.Fatalf("Send after the stale call returned %v, want ErrCircuitOpen while the trial runs", err)
+	}
+
+	close(probe.gate)
+	if err := <-probeDone; err != nil {
+		t.Fatalf("trial returned %v", err)
+	}
+
+	wantState(t, breaker, channel.BreakerClosed)
+}
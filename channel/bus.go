@@ -0,0 +1,189 @@
+package channel
+
+import (
+	"context"
+	"strings"
+	"sync"
+)
+
+const (
+	// matches exactly one topic segment
+	singleWildcard = "*"
+	// matches zero or more topic segments
+	multiWildcard = "#"
+)
+
+// Bus routes published values to the subscribers whose pattern matches the topic.
+// Topics and patterns are dot separated, e.g. orders.eu.created matches orders.*.created and orders.#.
+// Patterns are kept in a trie so a publish only walks the branches matching its topic.
+type Bus[T any] struct {
+	mu     sync.RWMutex
+	root   *topicNode[T]
+	subs   map[<-chan T]*busSubscriber[T]
+	buffer int
+}
+
+type topicNode[T any] struct {
+	children map[string]*topicNode[T]
+	subs     []*busSubscriber[T]
+}
+
+type busSubscriber[T any] struct {
+	segments []string
+	// held for reading while delivering, so Unsubscribe closes ch once no publish is writing to it
+	mu sync.RWMutex
+	ch chan T
+	// closed on unsubscribe so a publisher blocked on a full ch gives up
+	done    chan struct{}
+	once    sync.Once
+	options options[T]
+}
+
+// NewBus creates a bus whose subscriptions buffer up to buffer values
+func NewBus[T any](buffer int) *Bus[T] {
+	return &Bus[T]{
+		root:   newTopicNode[T](),
+		subs:   map[<-chan T]*busSubscriber[T]{},
+		buffer: buffer,
+	}
+}
+
+func newTopicNode[T any]() *topicNode[T] {
+	return &topicNode[T]{children: map[string]*topicNode[T]{}}
+}
+
+// Subscribe returns a channel receiving every value published on a topic matching pattern
+// and accepted by the middlewares in params. WithOverflow decides what happens when it is full,
+// DropNewest by default so that a subscriber not reading does not hold up every publisher.
+func (b *Bus[T]) Subscribe(pattern string, params ...WithOptions[T]) <-chan T {
+	opts := newOptions(params)
+	if !opts.overflowSet {
+		opts.overflow = DropNewest
+	}
+
+	sub := &busSubscriber[T]{
+		segments: strings.Split(pattern, "."),
+		ch:       make(chan T, b.buffer),
+		done:     make(chan struct{}),
+		options:  opts,
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	node := b.root
+	for _, segment := range sub.segments {
+		next, ok := node.children[segment]
+		if !ok {
+			next = newTopicNode[T]()
+			node.children[segment] = next
+		}
+
+		node = next
+	}
+
+	node.subs = append(node.subs, sub)
+	b.subs[sub.ch] = sub
+	return sub.ch
+}
+
+// Unsubscribe removes the subscription and closes its channel
+func (b *Bus[T]) Unsubscribe(ch <-chan T) {
+	b.mu.RLock()
+	sub, ok := b.subs[ch]
+	b.mu.RUnlock()
+
+	if !ok {
+		return
+	}
+
+	// release publishers blocked on this subscriber before waiting for the write lock
+	sub.once.Do(func() {
+		close(sub.done)
+	})
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if _, ok := b.subs[ch]; !ok {
+		return
+	}
+
+	delete(b.subs, ch)
+	b.root.remove(sub, sub.segments)
+
+	// publishers that matched sub before it was removed see done once they get the lock
+	sub.mu.Lock()
+	defer sub.mu.Unlock()
+	close(sub.ch)
+}
+
+func (b *Bus[T]) Publish(topic string, data T) {
+	b.PublishContext(context.Background(), topic, data)
+}
+
+// PublishContext delivers data to the matching subscribers, the trie is only locked while matching
+// so a subscriber blocking with WithOverflow(Block) does not hold up Subscribe and Unsubscribe
+func (b *Bus[T]) PublishContext(ctx context.Context, topic string, data T) {
+	matched := map[*busSubscriber[T]]struct{}{}
+	b.mu.RLock()
+	b.root.match(strings.Split(topic, "."), matched)
+	b.mu.RUnlock()
+
+	for sub := range matched {
+		_ = sub.options.run(ctx, sub.options.copyOf(data), sub.deliver)
+	}
+}
+
+// deliver is a no-op once the subscriber is unsubscribed
+func (s *busSubscriber[T]) deliver(data T) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	select {
+	case <-s.done:
+		return
+	default:
+	}
+
+	offer(s.ch, data, s.options.overflow, s.done)
+}
+
+func (n *topicNode[T]) match(segments []string, matched map[*busSubscriber[T]]struct{}) {
+	if len(segments) == 0 {
+		for _, sub := range n.subs {
+			matched[sub] = struct{}{}
+		}
+	} else {
+		if next, ok := n.children[segments[0]]; ok {
+			next.match(segments[1:], matched)
+		}
+
+		if next, ok := n.children[singleWildcard]; ok {
+			next.match(segments[1:], matched)
+		}
+	}
+
+	if next, ok := n.children[multiWildcard]; ok {
+		// # swallows from zero to all the remaining segments
+		for idx := 0; idx <= len(segments); idx++ {
+			next.match(segments[idx:], matched)
+		}
+	}
+}
+
+// remove reports whether n became empty so the parent can drop it
+func (n *topicNode[T]) remove(sub *busSubscriber[T], segments []string) bool {
+	if len(segments) == 0 {
+		for idx := range n.subs {
+			if n.subs[idx] == sub {
+				n.subs = append(n.subs[:idx], n.subs[idx+1:]...)
+				break
+			}
+		}
+	} else if next, ok := n.children[segments[0]]; ok && next.remove(sub, segments[1:]) {
+		delete(n.children, segments[0])
+	}
+
+	return len(n.subs) == 0 && len(n.children) == 0
+}
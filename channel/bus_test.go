@@ -0,0 +1,121 @@
+package channel
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBusMatch(t *testing.T) {
+	tests := []struct {
+		pattern string
+		topic   string
+		match   bool
+	}{
+		{pattern: "orders.created", topic: "orders.created", match: true},
+		{pattern: "orders.created", topic: "orders.updated"},
+		{pattern: "orders.created", topic: "orders.created.eu"},
+		{pattern: "orders.*", topic: "orders.created", match: true},
+		{pattern: "orders.*", topic: "orders"},
+		{pattern: "orders.*", topic: "orders.eu.created"},
+		{pattern: "orders.*.created", topic: "orders.eu.created", match: true},
+		{pattern: "orders.#", topic: "orders", match: true},
+		{pattern: "orders.#", topic: "orders.created", match: true},
+		{pattern: "orders.#", topic: "orders.eu.created", match: true},
+		{pattern: "orders.#", topic: "payments.created"},
+		{pattern: "#.created", topic: "orders.eu.created", match: true},
+		{pattern: "#.created", topic: "orders.eu.updated"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern+" on "+tt.topic, func(t *testing.T) {
+			bus := NewBus[string](1)
+			ch := bus.Subscribe(tt.pattern)
+			defer bus.Unsubscribe(ch)
+
+			bus.Publish(tt.topic, tt.topic)
+			select {
+			case got := <-ch:
+				if !tt.match || got != tt.topic {
+					t.Fatalf("received %q, want no match", got)
+				}
+			default:
+				if tt.match {
+					t.Fatal("received nothing, want a match")
+				}
+			}
+		})
+	}
+}
+
+func TestBusSubscriptionMiddlewares(t *testing.T) {
+	bus := NewBus[int](4)
+	all := bus.Subscribe("numbers")
+	even := bus.Subscribe("numbers", WithMiddleware(func(v int) bool { return v%2 == 0 }))
+
+	for idx := range 4 {
+		bus.Publish("numbers", idx)
+	}
+
+	if len(all) != 4 || len(even) != 2 {
+		t.Fatalf("subscriptions got %d and %d values, want 4 and 2", len(all), len(even))
+	}
+}
+
+func TestBusUnsubscribe(t *testing.T) {
+	bus := NewBus[int](1)
+	a := bus.Subscribe("orders.*.created")
+	b := bus.Subscribe("orders.#")
+
+	bus.Unsubscribe(a)
+	if _, ok := <-a; ok {
+		t.Fatal("unsubscribed channel still open")
+	}
+
+	bus.Publish("orders.eu.created", 1)
+	if got := <-b; got != 1 {
+		t.Fatalf("remaining subscription got %d, want 1", got)
+	}
+
+	// unsubscribing twice does nothing
+	bus.Unsubscribe(a)
+	bus.Unsubscribe(b)
+
+	if len(bus.subs) != 0 || len(bus.root.children) != 0 || len(bus.root.subs) != 0 {
+		t.Fatalf("trie not cleaned up: %d subscriptions, %d branches", len(bus.subs), len(bus.root.children))
+	}
+}
+
+func TestBusStalledSubscriberDoesNotBlock(t *testing.T) {
+	bus := NewBus[int](1)
+	stalled := bus.Subscribe("numbers")
+	defer bus.Unsubscribe(stalled)
+
+	live := bus.Subscribe("numbers")
+	defer bus.Unsubscribe(live)
+
+	within(t, time.Second, func() {
+		for idx := range 100 {
+			bus.Publish("numbers", idx)
+			<-live
+		}
+	})
+
+	if got := len(stalled); got != 1 {
+		t.Fatalf("stalled subscriber got %d values, want its buffer of 1", got)
+	}
+
+	// a publisher waiting on a blocking subscriber holds up neither Subscribe nor Unsubscribe
+	blocking := bus.Subscribe("numbers", WithOverflow[int](Block))
+	bus.Publish("numbers", 0)
+	published := make(chan struct{})
+	go func() {
+		defer close(published)
+		bus.Publish("numbers", 1)
+	}()
+
+	within(t, time.Second, func() {
+		bus.Unsubscribe(bus.Subscribe("numbers"))
+		bus.Unsubscribe(blocking)
+		<-published
+	})
+}
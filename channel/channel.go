@@ -0,0 +1,429 @@
+package channel
+
+import (
+	"context"
+	"errors"
+	"iter"
+	"sync"
+)
+
+// WithOverflow decides what Send does when the buffer of a Channel is full, Block by default,
+// or what a Fanout does with a subscriber whose buffer is full, DropNewest by default so that
+// a subscriber that stopped reading costs the others nothing.
+// A Fanout blocked on a subscriber that stopped reading is released by closing that subscription.
+func WithOverflow[T any](policy OverflowPolicy) WithOptions[T] {
+	return func(o *options[T]) {
+		o.overflow = policy
+		o.overflowSet = true
+	}
+}
+
+// WithContext shuts down when ctx is done: a Channel is closed as by Close,
+// a Fanout is stopped as by Stop
+func WithContext[T any](ctx context.Context) WithOptions[T] {
+	return func(o *options[T]) {
+		o.shutdown = ctx
+	}
+}
+
+// Channel is a buffered channel running the middlewares on Send
+type Channel[T any] struct {
+	mu     sync.Mutex
+	queue  queue[T]
+	size   int
+	closed bool
+	// closed and replaced on change so waiters can select on it
+	changed chan struct{}
+	waiting bool
+	// closed once the channel is closed and every buffered value was received
+	done     chan struct{}
+	finished bool
+	// above the high water mark, until the fill goes back to the low one
+	high    bool
+	options options[T]
+	// closed by the first Close or CloseNow
+	stopped chan struct{}
+	// every SendContext in flight and the goroutines it started
+	running sync.WaitGroup
+
+	// fed by a goroutine calling Recv, created by the first call to C
+	pump     chan T
+	pumpOnce sync.Once
+	// closed once that goroutine exited, set under mu
+	pumpDone chan struct{}
+	// closed by CloseNow, the goroutine of C then drops the value it holds
+	discarded chan struct{}
+}
+
+func New[T any](buffer int, params ...WithOptions[T]) (*Channel[T], error) {
+	if buffer < 1 {
+		return nil, errors.New("channel: buffer must be at least 1")
+	}
+
+	opts := newOptions(params)
+
+	var q queue[T] = newFifo[T](buffer)
+	if opts.priority != nil {
+		q = newPriorityQueue[T](buffer, opts.priority)
+	}
+
+	c := &Channel[T]{
+		queue:     q,
+		size:      buffer,
+		changed:   make(chan struct{}),
+		done:      make(chan struct{}),
+		options:   opts,
+		stopped:   make(chan struct{}),
+		discarded: make(chan struct{}),
+	}
+
+	c.options.running = &c.running
+	// values spilled before a restart come first
+	c.refill()()
+	if opts.shutdown != nil {
+		context.AfterFunc(opts.shutdown, func() {
+			_ = c.Close()
+		})
+	}
+
+	return c, nil
+}
+
+func (c *Channel[T]) Send(data T) error {
+	return c.SendContext(context.Background(), data)
+}
+
+// SendContext runs the middlewares then buffers data following the overflow policy.
+// It returns the reason data was not buffered: the middleware error, ErrFull with DropNewest,
+// ErrClosed, or ctx.Err() while blocked.
+func (c *Channel[T]) SendContext(ctx context.Context, data T) error {
+	// counted under mu so that Wait, which only starts once closed, never misses a goroutine
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return ErrClosed
+	}
+
+	c.running.Add(1)
+	c.mu.Unlock()
+	defer c.running.Done()
+
+	for idx := range c.options.sideEffect {
+		c.running.Add(1)
+		go func(sideEffect func(T), data T) {
+			defer c.running.Done()
+			sideEffect(data)
+		}(c.options.sideEffect[idx], data)
+	}
+
+	data = c.options.copyOf(data)
+	if c.options.pipeline.splitters == 0 {
+		data, err := c.options.runValue(ctx, data)
+		if err != nil {
+			return err
+		}
+
+		return c.enqueue(ctx, data)
+	}
+
+	var enqueueErr error
+	err := c.options.run(ctx, data, func(value T) {
+		if err := c.enqueue(ctx, value); err != nil && enqueueErr == nil {
+			enqueueErr = err
+		}
+	})
+
+	if err != nil {
+		return err
+	}
+
+	return enqueueErr
+}
+
+func (c *Channel[T]) enqueue(ctx context.Context, data T) error {
+	for {
+		c.mu.Lock()
+		if c.closed {
+			c.mu.Unlock()
+			return ErrClosed
+		}
+
+		if c.spilling() {
+			err := c.options.spill.Append(data)
+			c.mu.Unlock()
+			return err
+		}
+
+		if c.queue.len() < c.size {
+			c.push(data)
+			crossed := c.watermark()
+			c.mu.Unlock()
+
+			crossed()
+			return nil
+		}
+
+		switch c.options.overflow {
+		case DropNewest:
+			c.mu.Unlock()
+			return ErrFull
+		case DropOldest:
+			c.queue.evict()
+			c.push(data)
+			c.mu.Unlock()
+			return nil
+		}
+
+		changed := c.wait()
+		c.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-changed:
+		}
+	}
+}
+
+// Recv blocks until a value is available, ok is false once the channel is closed and empty,
+// including the values spilled with WithSpill. ok only tells about the channel, a zero value with ok
+// set was sent as is, dropped values are never returned.
+// Values older than WithTTL are dropped here rather than returned.
+func (c *Channel[T]) Recv() (data T, ok bool) {
+	for {
+		c.mu.Lock()
+		unreadable := c.refill()
+		if c.queue.len() > 0 {
+			data = c.pop()
+			crossed := c.watermark()
+			c.mu.Unlock()
+
+			unreadable()
+			crossed()
+
+			if c.expired(data) {
+				c.options.drop(context.Background(), data, ErrExpired)
+				continue
+			}
+
+			return data, true
+		}
+
+		if c.closed {
+			c.mu.Unlock()
+			unreadable()
+			return data, false
+		}
+
+		changed := c.wait()
+		c.mu.Unlock()
+
+		unreadable()
+		<-changed
+	}
+}
+
+// C returns a channel receiving the values of Recv, for use in select and range.
+// It is fed by a goroutine started on the first call, itself a Recv caller: mixing C and Recv
+// is safe but each value goes to whichever of them asked first, pick one per channel.
+// C is closed once the channel is closed and drained. Until then the goroutine holds
+// the next value, so stopping to read C keeps it alive until CloseNow, Iter has no such goroutine.
+func (c *Channel[T]) C() <-chan T {
+	c.pumpOnce.Do(func() {
+		pumpDone := make(chan struct{})
+		c.mu.Lock()
+		c.pump, c.pumpDone = make(chan T), pumpDone
+		c.mu.Unlock()
+
+		go func() {
+			defer close(pumpDone)
+			defer close(c.pump)
+
+			for {
+				data, ok := c.Recv()
+				if !ok {
+					return
+				}
+
+				select {
+				case c.pump <- data:
+				case <-c.discarded:
+					return
+				}
+			}
+		}()
+	})
+
+	return c.pump
+}
+
+// Iter ranges over the values of Recv until the channel is closed and drained.
+// Breaking out of the loop consumes nothing more, the remaining values stay buffered.
+func (c *Channel[T]) Iter() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for {
+			data, ok := c.Recv()
+			if !ok || !yield(data) {
+				return
+			}
+		}
+	}
+}
+
+// TryRecv is Recv without blocking, it tells apart the three states a poll can observe:
+//   - received and open: data was buffered and is returned
+//   - not received and open: nothing is buffered right now, data is the zero value
+//   - neither: the channel is closed and drained, nothing will ever be received
+//
+// Like ok of Recv, open is false only once the channel is closed and drained: a closed channel
+// still holding values reports them as received and open.
+func (c *Channel[T]) TryRecv() (data T, received, open bool) {
+	for {
+		c.mu.Lock()
+		unreadable := c.refill()
+		if c.queue.len() == 0 {
+			closed := c.closed
+			c.mu.Unlock()
+			unreadable()
+			return data, false, !closed
+		}
+
+		data = c.pop()
+		crossed := c.watermark()
+		c.mu.Unlock()
+
+		unreadable()
+		crossed()
+
+		if c.expired(data) {
+			var zero T
+			c.options.drop(context.Background(), data, ErrExpired)
+			data = zero
+			continue
+		}
+
+		return data, true, true
+	}
+}
+
+// Close stops accepting values: Send returns ErrClosed from now on, including a Send blocked
+// on a full buffer. Values already buffered went through the middlewares on Send and can still be
+// received, Recv reports the closure only once they are all drained, which also fires Done.
+func (c *Channel[T]) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		return ErrClosed
+	}
+
+	c.stop()
+	return nil
+}
+
+// CloseNow is Close but discards the buffered values, Done fires right away
+func (c *Channel[T]) CloseNow() error {
+	c.mu.Lock()
+	if c.closed && c.finished {
+		c.mu.Unlock()
+		return ErrClosed
+	}
+
+	c.queue.clear()
+	select {
+	case <-c.discarded:
+	default:
+		close(c.discarded)
+	}
+
+	if c.options.spill != nil {
+		// nothing to report, the values are discarded on purpose
+		_ = c.options.spill.Truncate(c.options.spill.Len())
+	}
+	c.stop()
+	crossed := c.watermark()
+	c.mu.Unlock()
+
+	crossed()
+	return nil
+}
+
+// Done is closed once the channel is closed and drained
+func (c *Channel[T]) Done() <-chan struct{} {
+	return c.done
+}
+
+// Wait blocks until the channel is closed, by Close, CloseNow or the context of WithContext,
+// and every Send in flight returned along with the side effects and WithTimeout calls it started.
+// It does not wait for the buffered values to be received, use Done for that, except for the
+// goroutine of C when C was called before: it exits once C is drained or CloseNow discarded the rest.
+// A middleware that ignores its context and never returns after a timeout keeps Wait blocked.
+func (c *Channel[T]) Wait() {
+	<-c.stopped
+	c.running.Wait()
+
+	c.mu.Lock()
+	pumpDone := c.pumpDone
+	c.mu.Unlock()
+
+	if pumpDone != nil {
+		<-pumpDone
+	}
+}
+
+func (c *Channel[T]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.queue.len() + c.spilled()
+}
+
+func (c *Channel[T]) Cap() int {
+	return c.size
+}
+
+// Stats returns the counters of every named middleware, unnamed ones are grouped under "anonymous".
+// It is nil without WithStats.
+func (c *Channel[T]) Stats() map[string]MiddlewareStat {
+	return c.options.stats.snapshot()
+}
+
+// stop, push, pop and notify must be called with mu held
+func (c *Channel[T]) stop() {
+	if !c.closed {
+		c.closed = true
+		close(c.stopped)
+	}
+
+	c.notify()
+}
+
+func (c *Channel[T]) push(data T) {
+	c.queue.push(data)
+	c.notify()
+}
+
+func (c *Channel[T]) pop() T {
+	data := c.queue.pop()
+	c.notify()
+	return data
+}
+
+// wait returns the channel closed by the next notify
+func (c *Channel[T]) wait() <-chan struct{} {
+	c.waiting = true
+	return c.changed
+}
+
+// notify only allocates a new changed channel when somebody is waiting on the current one
+func (c *Channel[T]) notify() {
+	if c.waiting {
+		close(c.changed)
+		c.changed = make(chan struct{})
+		c.waiting = false
+	}
+
+	if c.closed && c.queue.len() == 0 && c.spilled() == 0 && !c.finished {
+		c.finished = true
+		close(c.done)
+	}
+}
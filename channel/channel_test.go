@@ -0,0 +1,351 @@
+package channel_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/lamlv2305/rok/channel"
+	"go.uber.org/goleak"
+)
+
+func TestClose(t *testing.T) {
+	c, err := channel.New[int](4)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for idx := range 3 {
+		_ = c.Send(idx)
+	}
+
+	if err := c.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := c.Close(); !errors.Is(err, channel.ErrClosed) {
+		t.Fatalf("second Close returned %v, want ErrClosed", err)
+	}
+
+	if err := c.Send(3); !errors.Is(err, channel.ErrClosed) {
+		t.Fatalf("Send after Close returned %v, want ErrClosed", err)
+	}
+
+	// buffered values are still received, Done only fires once they are
+	for want := range 3 {
+		select {
+		case <-c.Done():
+			t.Fatal("Done fired before the buffer was drained")
+		default:
+		}
+
+		if got, ok := c.Recv(); !ok || got != want {
+			t.Fatalf("Recv got %d, %v, want %d", got, ok, want)
+		}
+	}
+
+	if _, ok := c.Recv(); ok {
+		t.Fatal("Recv reported a value once closed and drained")
+	}
+
+	select {
+	case <-c.Done():
+	case <-time.After(time.Second):
+		t.Fatal("Done did not fire once drained")
+	}
+}
+
+func TestCloseNow(t *testing.T) {
+	c, err := channel.New[int](4)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for idx := range 3 {
+		_ = c.Send(idx)
+	}
+
+	if err := c.CloseNow(); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case <-c.Done():
+	default:
+		t.Fatal("Done did not fire right away")
+	}
+
+	if v, ok := c.Recv(); ok {
+		t.Fatalf("Recv got %d after CloseNow, want nothing", v)
+	}
+}
+
+func TestSendCloseRace(t *testing.T) {
+	for range 50 {
+		c, err := channel.New[int](8, channel.WithOverflow[int](channel.Block))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var wg sync.WaitGroup
+		for idx := range 16 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				// some block on the full buffer until Close releases them
+				if err := c.Send(idx); err != nil && !errors.Is(err, channel.ErrClosed) {
+					t.Errorf("Send returned %v, want nil or ErrClosed", err)
+				}
+			}()
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_ = c.Close()
+		}()
+
+		wg.Wait()
+		received := 0
+		for range c.Iter() {
+			received++
+		}
+
+		if received > 8 {
+			t.Fatalf("received %d values from a buffer of 8", received)
+		}
+
+		<-c.Done()
+	}
+}
+
+func TestTryRecv(t *testing.T) {
+	c, err := channel.New[int](4)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, received, open := c.TryRecv(); received || !open {
+		t.Fatalf("empty channel reported received %v, open %v", received, open)
+	}
+
+	// a zero value is told apart from an empty buffer
+	_ = c.Send(0)
+	_ = c.Send(7)
+	if got, received, open := c.TryRecv(); got != 0 || !received || !open {
+		t.Fatalf("TryRecv got %d, %v, %v, want the zero value received", got, received, open)
+	}
+
+	_ = c.Close()
+	if got, received, open := c.TryRecv(); got != 7 || !received || !open {
+		t.Fatalf("closed channel still buffering got %d, %v, %v, want 7 received and open", got, received, open)
+	}
+
+	if _, received, open := c.TryRecv(); received || open {
+		t.Fatalf("closed and drained channel reported received %v, open %v", received, open)
+	}
+}
+
+func TestConsumption(t *testing.T) {
+	styles := []struct {
+		name string
+		// receive reads up to n values, stopping early when n is positive
+		receive func(c *channel.Channel[int], n int) []int
+	}{
+		{
+			name: "C",
+			receive: func(c *channel.Channel[int], n int) []int {
+				var got []int
+				for v := range c.C() {
+					got = append(got, v)
+					if len(got) == n {
+						break
+					}
+				}
+
+				return got
+			},
+		},
+		{
+			name: "Iter",
+			receive: func(c *channel.Channel[int], n int) []int {
+				var got []int
+				for v := range c.Iter() {
+					got = append(got, v)
+					if len(got) == n {
+						break
+					}
+				}
+
+				return got
+			},
+		},
+	}
+
+	for _, tt := range styles {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := channel.New[int](5)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for idx := range 5 {
+				_ = c.Send(idx)
+			}
+			_ = c.Close()
+
+			got := tt.receive(c, 0)
+			if len(got) != 5 || got[0] != 0 || got[4] != 4 {
+				t.Fatalf("ranged over %v, want 0..4 then the end once closed and drained", got)
+			}
+		})
+	}
+
+	t.Run("Iter early break", func(t *testing.T) {
+		c, err := channel.New[int](5)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for idx := range 5 {
+			_ = c.Send(idx)
+		}
+
+		if got := styles[1].receive(c, 2); len(got) != 2 {
+			t.Fatalf("received %v, want 2 values", got)
+		}
+
+		if c.Len() != 3 {
+			t.Fatalf("%d values left buffered after the break, want 3", c.Len())
+		}
+
+		if got, ok := c.Recv(); !ok || got != 2 {
+			t.Fatalf("Recv after the break got %d, %v, want 2", got, ok)
+		}
+	})
+
+	t.Run("C early break", func(t *testing.T) {
+		defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+
+		c, err := channel.New[int](5)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for idx := range 5 {
+			_ = c.Send(idx)
+		}
+
+		if got := styles[0].receive(c, 2); len(got) != 2 {
+			t.Fatalf("received %v, want 2 values", got)
+		}
+
+		// the goroutine of C waits with the next value until CloseNow releases it
+		if err := c.CloseNow(); err != nil {
+			t.Fatal(err)
+		}
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			for range c.C() {
+			}
+		}()
+
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatal("C still open after CloseNow")
+		}
+	})
+}
+
+func TestOverflow(t *testing.T) {
+	tests := []struct {
+		name   string
+		params []channel.WithOptions[int]
+		// sent in order to a buffer of 3, only the last send overflows
+		sends   []int
+		lastErr error
+		want    []int
+	}{
+		{
+			name:    "drop newest",
+			params:  []channel.WithOptions[int]{channel.WithOverflow[int](channel.DropNewest)},
+			sends:   []int{1, 2, 3, 4},
+			lastErr: channel.ErrFull,
+			want:    []int{1, 2, 3},
+		},
+		{
+			name:   "drop oldest",
+			params: []channel.WithOptions[int]{channel.WithOverflow[int](channel.DropOldest)},
+			sends:  []int{1, 2, 3, 4},
+			want:   []int{2, 3, 4},
+		},
+		{
+			name: "drop oldest with priority evicts the lowest",
+			params: []channel.WithOptions[int]{
+				channel.WithOverflow[int](channel.DropOldest),
+				channel.WithPriority(func(v int) int { return v }),
+			},
+			sends: []int{5, 1, 3, 4},
+			want:  []int{5, 4, 3},
+		},
+		{
+			name:    "block until ctx is done",
+			sends:   []int{1, 2, 3, 4},
+			lastErr: context.DeadlineExceeded,
+			want:    []int{1, 2, 3},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := channel.New(3, tt.params...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for idx, v := range tt.sends {
+				ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+				err := c.SendContext(ctx, v)
+				cancel()
+
+				want := error(nil)
+				if idx == len(tt.sends)-1 {
+					want = tt.lastErr
+				}
+
+				if !errors.Is(err, want) {
+					t.Fatalf("Send(%d) returned %v, want %v", v, err, want)
+				}
+			}
+
+			_ = c.Close()
+			var got []int
+			for v := range c.Iter() {
+				got = append(got, v)
+			}
+
+			if len(got) != len(tt.want) {
+				t.Fatalf("received %v, want %v", got, tt.want)
+			}
+
+			for idx := range got {
+				if got[idx] != tt.want[idx] {
+					t.Fatalf("received %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestNewBuffer(t *testing.T) {
+	for _, buffer := range []int{0, -1} {
+		if _, err := channel.New[int](buffer); err == nil {
+			t.Fatalf("New(%d) returned no error", buffer)
+		}
+	}
+}
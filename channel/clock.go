@@ -0,0 +1,108 @@
+package channel
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+)
+
+// Clock is the time source of every time based stage and helper, see WithClock
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+	NewTicker(d time.Duration) Ticker
+	AfterFunc(d time.Duration, f func()) Timer
+}
+
+type Ticker interface {
+	C() <-chan time.Time
+	Stop()
+}
+
+// Timer is what AfterFunc returns, Stop reports whether it prevented f from running
+type Timer interface {
+	Stop() bool
+}
+
+// WithClock replaces the system clock, e.g. with fakeclock in tests. It is the only way time is
+// injected: the stage it is given to and every stage registered on it follow that clock, which covers
+// TTLs, timeouts, batching, debouncing, windows, metrics durations, Dedupe, RateLimit, Retry and Breaker.
+// A stage registered in several places follows the clock of the last one built with WithClock.
+func WithClock[T any](clock Clock) WithOptions[T] {
+	return func(o *options[T]) {
+		o.clock = clock
+	}
+}
+
+// clockRef is the clock of a helper stage, which WithClock rebinds once the stage is registered
+type clockRef struct {
+	clock atomic.Pointer[Clock]
+}
+
+func newClockRef() *clockRef {
+	ref := &clockRef{}
+	ref.set(systemClock{})
+	return ref
+}
+
+func (r *clockRef) get() Clock {
+	return *r.clock.Load()
+}
+
+func (r *clockRef) set(clock Clock) {
+	r.clock.Store(&clock)
+}
+
+// bindAll binds every part of a composite stage
+func bindAll[T any](steps ...step[T]) func(Clock) {
+	return func(clock Clock) {
+		for idx := range steps {
+			if steps[idx].clock != nil {
+				steps[idx].clock(clock)
+			}
+		}
+	}
+}
+
+// systemClock is the default Clock, backed by the time package
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (systemClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+func (systemClock) NewTicker(d time.Duration) Ticker {
+	return systemTicker{time.NewTicker(d)}
+}
+
+func (systemClock) AfterFunc(d time.Duration, f func()) Timer {
+	return time.AfterFunc(d, f)
+}
+
+type systemTicker struct {
+	*time.Ticker
+}
+
+func (t systemTicker) C() <-chan time.Time {
+	return t.Ticker.C
+}
+
+// sleep waits for d on clock, it returns ctx.Err() if ctx is done first
+func sleep(ctx context.Context, clock Clock, d time.Duration) error {
+	fired := make(chan struct{})
+	timer := clock.AfterFunc(d, func() {
+		close(fired)
+	})
+
+	select {
+	case <-ctx.Done():
+		timer.Stop()
+		return ctx.Err()
+	case <-fired:
+		return nil
+	}
+}
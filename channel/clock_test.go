@@ -0,0 +1,135 @@
+package channel_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/lamlv2305/rok/channel"
+	"github.com/lamlv2305/rok/channel/fakeclock"
+)
+
+type stamped struct {
+	key string
+	at  time.Time
+}
+
+func TestWithClockTTL(t *testing.T) {
+	clock := fakeclock.New(time.Unix(0, 0))
+	var expired []string
+	c, err := channel.New(4,
+		channel.WithClock[stamped](clock),
+		channel.WithTTL(time.Second, func(v stamped) time.Time { return v.at }),
+		channel.WithErrorHandler(func(v stamped, err error) {
+			if errors.Is(err, channel.ErrExpired) {
+				expired = append(expired, v.key)
+			}
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := c.Send(stamped{key: "fresh", at: clock.Now()}); err != nil {
+		t.Fatal(err)
+	}
+
+	clock.Advance(time.Second)
+	if got, received, _ := c.TryRecv(); !received || got.key != "fresh" {
+		t.Fatalf("at the TTL boundary got %v, %v, want fresh", got, received)
+	}
+
+	if err := c.Send(stamped{key: "stale", at: clock.Now()}); err != nil {
+		t.Fatal(err)
+	}
+
+	clock.Advance(time.Second + time.Nanosecond)
+	if got, received, open := c.TryRecv(); received || !open {
+		t.Fatalf("past the TTL boundary got %v, %v, %v, want nothing", got, received, open)
+	}
+
+	if len(expired) != 1 || expired[0] != "stale" {
+		t.Fatalf("expired %v, want [stale]", expired)
+	}
+}
+
+func TestWithClockBindsStages(t *testing.T) {
+	clock := fakeclock.New(time.Unix(0, 0))
+	c, err := channel.New(16,
+		channel.WithClock[stamped](clock),
+		channel.WithStage(
+			channel.Dedupe(func(v stamped) string { return v.key }, time.Minute),
+			channel.RateLimit[stamped](1, 1),
+		),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	steps := []struct {
+		name    string
+		advance time.Duration
+		key     string
+		dropped bool
+	}{
+		{name: "first", key: "a"},
+		{name: "no token yet", key: "b", dropped: true},
+		{name: "token refilled", advance: time.Second, key: "c"},
+		{name: "duplicate within ttl", advance: time.Second, key: "a", dropped: true},
+		{name: "duplicate after ttl", advance: time.Minute, key: "a"},
+	}
+
+	for _, step := range steps {
+		clock.Advance(step.advance)
+		err := c.Send(stamped{key: step.key})
+		if dropped := errors.Is(err, channel.ErrDropped); dropped != step.dropped {
+			t.Fatalf("%s: Send returned %v, want dropped %v", step.name, err, step.dropped)
+		}
+	}
+}
+
+func TestWithClockTimeout(t *testing.T) {
+	clock := fakeclock.New(time.Unix(0, 0))
+	c, err := channel.New(1,
+		channel.WithClock[int](clock),
+		channel.WithTimeout[int](time.Second),
+		channel.WithCtxMiddleware(func(ctx context.Context, _ int) bool {
+			<-ctx.Done()
+			return false
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result := make(chan error, 1)
+	go func() {
+		result <- c.Send(1)
+	}()
+
+	deadline := time.Now().Add(time.Second)
+	for clock.Pending() == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("timeout never scheduled on the injected clock")
+		}
+
+		time.Sleep(time.Millisecond)
+	}
+
+	select {
+	case err := <-result:
+		t.Fatalf("Send returned %v before the clock moved", err)
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	clock.Advance(time.Second)
+	select {
+	case err := <-result:
+		if !errors.Is(err, channel.ErrTimeout) {
+			t.Fatalf("Send returned %v, want ErrTimeout", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Send still blocked after advancing past the timeout")
+	}
+}
@@ -0,0 +1,22 @@
+package channel
+
+// WithClone copies every value handed to a recipient with clone, so that recipients holding
+// pointers cannot see each other's mutations. Without it every recipient shares the value as sent.
+//   - Fanout clones for each subscriber, replayed values included.
+//   - Bus clones for each subscription given the option, before its middlewares run.
+//   - Channel has a single recipient and clones once on Send, which only protects from the sender.
+//
+// FanOut takes no options, each Target has its own Clone.
+func WithClone[T any](clone func(T) T) WithOptions[T] {
+	return func(o *options[T]) {
+		o.clone = clone
+	}
+}
+
+func (o *options[T]) copyOf(data T) T {
+	if o.clone == nil {
+		return data
+	}
+
+	return o.clone(data)
+}
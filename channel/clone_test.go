@@ -0,0 +1,81 @@
+package channel
+
+import (
+	"testing"
+	"time"
+)
+
+type order struct {
+	id     int
+	status string
+}
+
+func cloneOrder(o *order) *order {
+	copied := *o
+	return &copied
+}
+
+func TestClone(t *testing.T) {
+	tests := []struct {
+		name string
+		// receive returns the pointers handed to two recipients of the same sent value
+		receive func(t *testing.T, sent *order) (*order, *order)
+	}{
+		{
+			name: "fanout",
+			receive: func(t *testing.T, sent *order) (*order, *order) {
+				f := NewFanout(WithClone(cloneOrder))
+				a, b := f.Subscribe(1, nil), f.Subscribe(1, nil)
+				defer a.Close()
+				defer b.Close()
+
+				f.Send(sent)
+				return <-a.C(), <-b.C()
+			},
+		},
+		{
+			name: "fan out targets",
+			receive: func(t *testing.T, sent *order) (*order, *order) {
+				source := make(chan *order)
+				a, b := make(chan *order, 1), make(chan *order, 1)
+				stop := FanOut(source,
+					Target[*order]{Ch: a, Buffer: 1, Clone: cloneOrder},
+					Target[*order]{Ch: b, Buffer: 1, Clone: cloneOrder},
+				)
+				defer within(t, time.Second, stop)
+
+				source <- sent
+				return <-a, <-b
+			},
+		},
+		{
+			name: "bus",
+			receive: func(t *testing.T, sent *order) (*order, *order) {
+				bus := NewBus[*order](1)
+				a := bus.Subscribe("orders", WithClone(cloneOrder))
+				b := bus.Subscribe("orders", WithClone(cloneOrder))
+				defer bus.Unsubscribe(a)
+				defer bus.Unsubscribe(b)
+
+				bus.Publish("orders", sent)
+				return <-a, <-b
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sent := &order{id: 1, status: "new"}
+			a, b := tt.receive(t, sent)
+
+			a.status = "shipped"
+			if b.status != "new" || sent.status != "new" {
+				t.Fatalf("mutating one recipient changed the other to %q and the sent value to %q", b.status, sent.status)
+			}
+
+			if a == b || a == sent {
+				t.Fatal("recipients share a pointer")
+			}
+		})
+	}
+}
@@ -0,0 +1,36 @@
+// Package codec runs typed middlewares on a channel of encoded payloads.
+//
+//	raw := channel.NewFanout[[]byte](
+//		channel.WithTransform(codec.Through(codec.JSON, normalize)),
+//		channel.WithMiddleware(codec.Lift(codec.JSON, validate)),
+//	)
+//
+// A payload that does not decode, or no longer encodes, is dropped.
+package codec
+
+import "encoding/json"
+
+// Codec converts values to bytes and back, JSON is provided,
+// protobuf or msgpack only need a small adapter
+type Codec interface {
+	Marshal(v any) ([]byte, error)
+	Unmarshal(data []byte, v any) error
+}
+
+var JSON Codec = jsonCodec{}
+
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v any) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (jsonCodec) Unmarshal(data []byte, v any) error {
+	return json.Unmarshal(data, v)
+}
+
+func Decode[T any](c Codec, payload []byte) (T, error) {
+	var data T
+	err := c.Unmarshal(payload, &data)
+	return data, err
+}
@@ -0,0 +1,92 @@
+package codec_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/lamlv2305/rok/channel"
+	"github.com/lamlv2305/rok/channel/codec"
+)
+
+type order struct {
+	ID     int    `json:"id"`
+	Status string `json:"status"`
+	Items  []int  `json:"items"`
+}
+
+func TestJSONRoundTrip(t *testing.T) {
+	sent := order{ID: 7, Status: "new", Items: []int{1, 2}}
+	payload, err := codec.JSON.Marshal(sent)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := codec.Decode[order](codec.JSON, payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got.ID != sent.ID || got.Status != sent.Status || len(got.Items) != 2 || got.Items[1] != 2 {
+		t.Fatalf("decoded %+v, want %+v", got, sent)
+	}
+}
+
+func TestThrough(t *testing.T) {
+	ship := codec.Through(codec.JSON, func(o order) (order, bool) {
+		o.Status = "shipped"
+		return o, o.ID > 0
+	})
+
+	tests := []struct {
+		name    string
+		payload string
+		passes  bool
+		want    string
+	}{
+		{name: "rewritten", payload: `{"id":1,"status":"new"}`, passes: true, want: "shipped"},
+		{name: "rejected by the typed transform", payload: `{"id":0}`},
+		{name: "malformed", payload: `{"id":`},
+		{name: "wrong type", payload: `{"id":"one"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			raw, err := channel.New(1,
+				channel.WithTransform(ship),
+				channel.WithMiddleware(codec.Valid[order](codec.JSON)),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = raw.Send([]byte(tt.payload))
+			if (err == nil) != tt.passes {
+				t.Fatalf("Send returned %v, want passing %v", err, tt.passes)
+			}
+
+			if !tt.passes {
+				return
+			}
+
+			payload, _ := raw.Recv()
+			var got order
+			if err := json.Unmarshal(payload, &got); err != nil || got.Status != tt.want {
+				t.Fatalf("delivered %s, want status %s", payload, tt.want)
+			}
+		})
+	}
+}
+
+func TestLift(t *testing.T) {
+	small := codec.Lift(codec.JSON, func(o order) bool { return len(o.Items) < 3 })
+
+	for payload, want := range map[string]bool{
+		`{"items":[1]}`:       true,
+		`{"items":[1,2,3,4]}`: false,
+		`not json`:            false,
+	} {
+		if got := small([]byte(payload)); got != want {
+			t.Fatalf("Lift on %s returned %v, want %v", payload, got, want)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package codec
+
+import "github.com/lamlv2305/rok/channel"
+
+// Valid drops payloads that do not decode to T
+func Valid[T any](c Codec) channel.Middleware[[]byte] {
+	return func(payload []byte) bool {
+		_, err := Decode[T](c, payload)
+		return err == nil
+	}
+}
+
+// Lift runs m on the decoded payload, payloads that do not decode are dropped
+func Lift[T any](c Codec, m channel.Middleware[T]) channel.Middleware[[]byte] {
+	return func(payload []byte) bool {
+		data, err := Decode[T](c, payload)
+		return err == nil && m(data)
+	}
+}
+
+// Through decodes the payload, lets typed rewrite or drop it, then encodes the result back
+func Through[T any](c Codec, typed channel.Transform[T]) channel.Transform[[]byte] {
+	return func(payload []byte) ([]byte, bool) {
+		data, err := Decode[T](c, payload)
+		if err != nil {
+			return payload, false
+		}
+
+		data, ok := typed(data)
+		if !ok {
+			return payload, false
+		}
+
+		encoded, err := c.Marshal(data)
+		if err != nil {
+			return payload, false
+		}
+
+		return encoded, true
+	}
+}
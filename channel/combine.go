@@ -0,0 +1,83 @@
+package channel
+
+import (
+	"context"
+	"strings"
+)
+
+// All passes a value only if every stage accepts it, transforms apply in turn.
+// Stages run in order and the first one rejecting the value stops the others, the error then
+// names that stage. All of nothing passes everything.
+// All is registered under the names of its named stages joined with a comma, Splitters are not supported.
+func All[T any](stages ...Stage[T]) Stage[T] {
+	return stageFunc[T](func() step[T] {
+		steps, name := composite("All", stages)
+		return step[T]{
+			name:  name,
+			clock: bindAll(steps...),
+			fn: func(ctx context.Context, data T) (T, error) {
+				for idx := range steps {
+					result, err := steps[idx].fn(ctx, data)
+					if err != nil {
+						return data, attribute(steps[idx].name, err)
+					}
+
+					data = result
+				}
+
+				return data, nil
+			},
+		}
+	})
+}
+
+// Any passes a value if at least one stage accepts it, the value is then what that stage returned.
+// Stages run in order and the first one accepting the value stops the others.
+// Any of nothing drops everything.
+// Any is registered under the names of its named stages joined with a comma, Splitters are not supported.
+func Any[T any](stages ...Stage[T]) Stage[T] {
+	return stageFunc[T](func() step[T] {
+		steps, name := composite("Any", stages)
+		return step[T]{
+			name:  name,
+			clock: bindAll(steps...),
+			fn: func(ctx context.Context, data T) (T, error) {
+				err := ErrDropped
+				for idx := range steps {
+					var result T
+					if result, err = steps[idx].fn(ctx, data); err == nil {
+						return result, nil
+					}
+				}
+
+				return data, err
+			},
+		}
+	})
+}
+
+func composite[T any](kind string, stages []Stage[T]) ([]step[T], string) {
+	steps := make([]step[T], len(stages))
+	var names []string
+	for idx := range stages {
+		steps[idx] = stages[idx].step()
+		if steps[idx].split != nil {
+			panic("channel: " + kind + " does not support splitters")
+		}
+
+		if steps[idx].name != "" {
+			names = append(names, steps[idx].name)
+		}
+	}
+
+	return steps, strings.Join(names, ",")
+}
+
+// attribute names the part of a composite stage that rejected a value, when it has a name
+func attribute(name string, err error) error {
+	if name == "" {
+		return err
+	}
+
+	return stepError(name, err)
+}
@@ -0,0 +1,90 @@
+package channel_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/lamlv2305/rok/channel"
+)
+
+func TestCombine(t *testing.T) {
+	// record builds a middleware returning result and remembering it ran
+	var ran []string
+	record := func(name string, result bool) channel.Stage[int] {
+		return channel.Named(name, channel.Middleware[int](func(int) bool {
+			ran = append(ran, name)
+			return result
+		}))
+	}
+
+	tests := []struct {
+		name    string
+		stage   func() channel.Stage[int]
+		passes  bool
+		ran     []string
+		dropped string
+	}{
+		{name: "all of nothing", stage: func() channel.Stage[int] { return channel.All[int]() }, passes: true},
+		{name: "any of nothing", stage: func() channel.Stage[int] { return channel.Any[int]() }},
+		{
+			name: "all stops at the first false",
+			stage: func() channel.Stage[int] {
+				return channel.All(record("a", true), record("b", false), record("c", true))
+			},
+			ran: []string{"a", "b"}, dropped: "b",
+		},
+		{
+			name: "any stops at the first true",
+			stage: func() channel.Stage[int] {
+				return channel.Any(record("a", false), record("b", true), record("c", true))
+			},
+			passes: true, ran: []string{"a", "b"},
+		},
+		{
+			name:  "any runs everything when all fail",
+			stage: func() channel.Stage[int] { return channel.Any(record("a", false), record("b", false)) },
+			ran:   []string{"a", "b"}, dropped: "a,b",
+		},
+		{
+			name: "nested",
+			stage: func() channel.Stage[int] {
+				return channel.All(record("a", true), channel.Any(record("b", false), record("c", true)))
+			},
+			passes: true, ran: []string{"a", "b", "c"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ran = nil
+			var step string
+			_, ok := channel.Process(1,
+				channel.WithStage(tt.stage()),
+				channel.WithErrorHandler(func(_ int, err error) {
+					var stepErr *channel.StepError
+					if errors.As(err, &stepErr) {
+						step = stepErr.Step
+					}
+				}),
+			)
+
+			if ok != tt.passes {
+				t.Fatalf("passed %v, want %v", ok, tt.passes)
+			}
+
+			if len(ran) != len(tt.ran) {
+				t.Fatalf("ran %v, want %v", ran, tt.ran)
+			}
+
+			for idx := range ran {
+				if ran[idx] != tt.ran[idx] {
+					t.Fatalf("ran %v, want %v", ran, tt.ran)
+				}
+			}
+
+			if step != tt.dropped {
+				t.Fatalf("dropped by %q, want %q", step, tt.dropped)
+			}
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package channel
+
+import "context"
+
+type CtxMiddleware[T any] func(context.Context, T) bool
+
+func WithCtxMiddleware[T any](middlewares ...CtxMiddleware[T]) WithOptions[T] {
+	return func(o *options[T]) {
+		for idx := range middlewares {
+			o.pipeline.add(middlewares[idx].step())
+		}
+	}
+}
+
+func (m CtxMiddleware[T]) step() step[T] {
+	return step[T]{
+		fn: func(ctx context.Context, data T) (T, error) {
+			if !m(ctx, data) {
+				return data, ErrDropped
+			}
+
+			return data, nil
+		},
+	}
+}
+
+type CtxErrMiddleware[T any] func(context.Context, T) error
+
+func WithCtxErrMiddleware[T any](middlewares ...CtxErrMiddleware[T]) WithOptions[T] {
+	return func(o *options[T]) {
+		for idx := range middlewares {
+			o.pipeline.add(middlewares[idx].step())
+		}
+	}
+}
+
+func (m CtxErrMiddleware[T]) step() step[T] {
+	return step[T]{
+		fn: func(ctx context.Context, data T) (T, error) {
+			return data, m(ctx, data)
+		},
+	}
+}
@@ -0,0 +1,17 @@
+package channel
+
+// WithDeadLetter sends every rejected value to dlq instead of discarding it.
+// Use a *StepError from WithErrorHandler to know which middleware rejected it.
+// dlq is bidirectional so DropOldest can take a value out of it.
+func WithDeadLetter[T any](dlq chan T) WithOptions[T] {
+	return func(o *options[T]) {
+		o.deadLetter = dlq
+	}
+}
+
+// WithDeadLetterPolicy applies when the dead letter channel is full, Block by default
+func WithDeadLetterPolicy[T any](policy OverflowPolicy) WithOptions[T] {
+	return func(o *options[T]) {
+		o.deadLetterPolicy = policy
+	}
+}
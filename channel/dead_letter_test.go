@@ -0,0 +1,89 @@
+package channel_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/lamlv2305/rok/channel"
+)
+
+func TestDeadLetter(t *testing.T) {
+	dlq := make(chan int, 3)
+	var steps []string
+	c, err := channel.New(10,
+		channel.WithDeadLetter(dlq),
+		channel.WithDeadLetterPolicy[int](channel.Block),
+		channel.WithErrorHandler(func(_ int, err error) {
+			var stepErr *channel.StepError
+			if errors.As(err, &stepErr) {
+				steps = append(steps, stepErr.Step)
+			}
+		}),
+		channel.WithStage(channel.Named("not multiple of 3", channel.Middleware[int](func(v int) bool { return v%3 != 0 || v == 0 }))),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 3, 6 and 9 are rejected
+	for idx := range 10 {
+		_ = c.Send(idx)
+	}
+
+	if c.Len() != 7 {
+		t.Fatalf("buffered %d values, want 7", c.Len())
+	}
+
+	close(dlq)
+	var rejected []int
+	for v := range dlq {
+		rejected = append(rejected, v)
+	}
+
+	if len(rejected) != 3 || rejected[0] != 3 || rejected[1] != 6 || rejected[2] != 9 {
+		t.Fatalf("dead letters %v, want [3 6 9]", rejected)
+	}
+
+	if len(steps) != 3 || steps[0] != "not multiple of 3" {
+		t.Fatalf("rejected by %v, want the named middleware", steps)
+	}
+}
+
+func TestDeadLetterPolicy(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy channel.OverflowPolicy
+		want   []int
+	}{
+		{name: "drop newest", policy: channel.DropNewest, want: []int{0, 1}},
+		{name: "drop oldest", policy: channel.DropOldest, want: []int{3, 4}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dlq := make(chan int, 2)
+			c, err := channel.New(1,
+				channel.WithDeadLetter(dlq),
+				channel.WithDeadLetterPolicy[int](tt.policy),
+				channel.WithMiddleware(func(int) bool { return false }),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for idx := range 5 {
+				_ = c.Send(idx)
+			}
+
+			close(dlq)
+			var got []int
+			for v := range dlq {
+				got = append(got, v)
+			}
+
+			if len(got) != len(tt.want) || got[0] != tt.want[0] || got[1] != tt.want[1] {
+				t.Fatalf("dead letters %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,103 @@
+package channel
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Debouncer holds the last value of each key and emits it on Out once no newer value
+// with the same key arrived for wait, a burst of values collapses to its trailing one.
+type Debouncer[T any, K comparable] struct {
+	mu      sync.Mutex
+	key     func(T) K
+	wait    time.Duration
+	out     chan T
+	pending map[K]*debounced[T]
+	closed  bool
+	emits   sync.WaitGroup
+	options options[T]
+}
+
+type debounced[T any] struct {
+	data  T
+	timer Timer
+}
+
+func NewDebouncer[T any, K comparable](key func(T) K, wait time.Duration, params ...WithOptions[T]) *Debouncer[T, K] {
+	return &Debouncer[T, K]{
+		key:     key,
+		wait:    wait,
+		out:     make(chan T),
+		pending: map[K]*debounced[T]{},
+		options: newOptions(params),
+	}
+}
+
+func (d *Debouncer[T, K]) Out() <-chan T {
+	return d.out
+}
+
+// Send runs the middlewares on data and makes it the pending value of its key,
+// values sent after Close are ignored
+func (d *Debouncer[T, K]) Send(data T) {
+	_ = d.options.run(context.Background(), data, d.schedule)
+}
+
+func (d *Debouncer[T, K]) schedule(data T) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.closed {
+		return
+	}
+
+	key := d.key(data)
+	if prev, ok := d.pending[key]; ok {
+		prev.timer.Stop()
+	}
+
+	entry := &debounced[T]{data: data}
+	entry.timer = d.options.clock.AfterFunc(d.wait, func() {
+		d.mu.Lock()
+		// replaced by a newer value or flushed by Close
+		if d.pending[key] != entry {
+			d.mu.Unlock()
+			return
+		}
+
+		delete(d.pending, key)
+		d.emits.Add(1)
+		d.mu.Unlock()
+
+		defer d.emits.Done()
+		d.out <- entry.data
+	})
+
+	d.pending[key] = entry
+}
+
+// Close emits every pending value right away, then closes Out
+func (d *Debouncer[T, K]) Close() {
+	d.mu.Lock()
+	if d.closed {
+		d.mu.Unlock()
+		return
+	}
+
+	d.closed = true
+	pending := make([]T, 0, len(d.pending))
+	for key, entry := range d.pending {
+		entry.timer.Stop()
+		pending = append(pending, entry.data)
+		delete(d.pending, key)
+	}
+	d.mu.Unlock()
+
+	for idx := range pending {
+		d.out <- pending[idx]
+	}
+
+	d.emits.Wait()
+	close(d.out)
+}
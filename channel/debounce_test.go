@@ -0,0 +1,70 @@
+package channel_test
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/lamlv2305/rok/channel"
+	"github.com/lamlv2305/rok/channel/fakeclock"
+)
+
+type reading struct {
+	sensor string
+	value  int
+}
+
+func TestDebouncer(t *testing.T) {
+	clock := fakeclock.New(time.Unix(0, 0))
+	d := channel.NewDebouncer(func(r reading) string { return r.sensor }, 100*time.Millisecond, channel.WithClock[reading](clock))
+
+	// every value comes within the wait of the previous one of its sensor
+	for idx := range 3 {
+		d.Send(reading{sensor: "a", value: idx})
+		d.Send(reading{sensor: "b", value: idx})
+		clock.Advance(50 * time.Millisecond)
+	}
+
+	clock.Advance(50 * time.Millisecond)
+	got := map[string]int{}
+	for range 2 {
+		r := <-d.Out()
+		got[r.sensor] = r.value
+	}
+
+	if len(got) != 2 || got["a"] != 2 || got["b"] != 2 {
+		t.Fatalf("emitted %v, want the last value of each sensor", got)
+	}
+
+	// nothing else was pending
+	go d.Close()
+	if r, ok := <-d.Out(); ok {
+		t.Fatalf("emitted %v once every burst was flushed", r)
+	}
+}
+
+func TestDebouncerCloseFlushes(t *testing.T) {
+	clock := fakeclock.New(time.Unix(0, 0))
+	d := channel.NewDebouncer(func(r reading) string { return r.sensor }, time.Minute, channel.WithClock[reading](clock))
+
+	d.Send(reading{sensor: "a", value: 1})
+	d.Send(reading{sensor: "a", value: 2})
+	d.Send(reading{sensor: "b", value: 3})
+
+	// the clock never moves, only Close emits
+	go d.Close()
+
+	var got []int
+	for r := range d.Out() {
+		got = append(got, r.value)
+	}
+
+	slices.Sort(got)
+	if !slices.Equal(got, []int{2, 3}) {
+		t.Fatalf("Close emitted %v, want the pending [2 3]", got)
+	}
+
+	// ignored rather than emitted on the closed Out
+	d.Send(reading{sensor: "a", value: 4})
+	clock.Advance(time.Minute)
+}
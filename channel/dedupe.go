@@ -0,0 +1,102 @@
+package channel
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+// DedupeOption configures Dedupe
+type DedupeOption func(*dedupeOptions)
+
+type dedupeOptions struct {
+	maxKeys int
+}
+
+// WithMaxKeys caps how many keys Dedupe remembers, the least recently seen key is evicted first
+func WithMaxKeys(maxKeys int) DedupeOption {
+	return func(o *dedupeOptions) {
+		o.maxKeys = maxKeys
+	}
+}
+
+// Dedupe drops a value when a value with the same key was seen less than ttl ago,
+// a dropped duplicate counts as seen and pushes the expiry of its key back by ttl.
+// Expired keys are evicted as new values arrive, so memory follows the traffic of the last ttl.
+// The returned stage is safe for concurrent use, its state is shared by every pipeline it is registered in.
+func Dedupe[T any, K comparable](key func(T) K, ttl time.Duration, params ...DedupeOption) Stage[T] {
+	var opts dedupeOptions
+	for _, param := range params {
+		param(&opts)
+	}
+
+	d := &deduper[K]{
+		ttl:     ttl,
+		maxKeys: opts.maxKeys,
+		seen:    map[K]*list.Element{},
+		order:   list.New(),
+		clock:   newClockRef(),
+	}
+
+	return stageFunc[T](func() step[T] {
+		return step[T]{
+			fn: func(_ context.Context, data T) (T, error) {
+				if !d.admit(key(data)) {
+					return data, ErrDropped
+				}
+
+				return data, nil
+			},
+			clock: d.clock.set,
+		}
+	})
+}
+
+type dedupeEntry[K comparable] struct {
+	key     K
+	expires time.Time
+}
+
+type deduper[K comparable] struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	maxKeys int
+	seen    map[K]*list.Element
+	// least recently seen first, which is also the expiry order
+	order *list.List
+	clock *clockRef
+}
+
+func (d *deduper[K]) admit(key K) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	now := d.clock.get().Now()
+	for front := d.order.Front(); front != nil; front = d.order.Front() {
+		entry := front.Value.(dedupeEntry[K])
+		if now.Before(entry.expires) {
+			break
+		}
+
+		d.remove(front)
+	}
+
+	if el, ok := d.seen[key]; ok {
+		el.Value = dedupeEntry[K]{key: key, expires: now.Add(d.ttl)}
+		d.order.MoveToBack(el)
+		return false
+	}
+
+	d.seen[key] = d.order.PushBack(dedupeEntry[K]{key: key, expires: now.Add(d.ttl)})
+	if d.maxKeys > 0 && d.order.Len() > d.maxKeys {
+		d.remove(d.order.Front())
+	}
+
+	return true
+}
+
+func (d *deduper[K]) remove(el *list.Element) {
+	delete(d.seen, el.Value.(dedupeEntry[K]).key)
+	d.order.Remove(el)
+}
@@ -0,0 +1,106 @@
+package channel_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/lamlv2305/rok/channel"
+	"github.com/lamlv2305/rok/channel/fakeclock"
+)
+
+func TestDedupe(t *testing.T) {
+	type send struct {
+		advance time.Duration
+		key     string
+		dropped bool
+	}
+
+	tests := []struct {
+		name    string
+		maxKeys int
+		sends   []send
+	}{
+		{
+			name: "same key within ttl",
+			sends: []send{
+				{key: "a"},
+				{advance: 30 * time.Second, key: "a", dropped: true},
+				{advance: 29 * time.Second, key: "a", dropped: true},
+			},
+		},
+		{
+			name: "same key after ttl",
+			sends: []send{
+				{key: "a"},
+				{advance: time.Minute, key: "a"},
+				{advance: time.Minute + time.Second, key: "a"},
+			},
+		},
+		{
+			name: "a duplicate pushes the expiry back",
+			sends: []send{
+				{key: "a"},
+				{advance: 30 * time.Second, key: "a", dropped: true},
+				{advance: 45 * time.Second, key: "a", dropped: true},
+				{advance: time.Minute, key: "a"},
+			},
+		},
+		{
+			name:    "max keys evicts the least recently seen",
+			maxKeys: 2,
+			sends: []send{
+				{key: "a"},
+				{key: "b"},
+				{key: "a", dropped: true},
+				{key: "c"},
+				{key: "a", dropped: true},
+				{key: "b"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := fakeclock.New(time.Unix(0, 0))
+			var params []channel.DedupeOption
+			if tt.maxKeys > 0 {
+				params = append(params, channel.WithMaxKeys(tt.maxKeys))
+			}
+
+			c, err := channel.New(len(tt.sends),
+				channel.WithClock[string](clock),
+				channel.WithStage(channel.Dedupe(func(v string) string { return v }, time.Minute, params...)),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for idx, s := range tt.sends {
+				clock.Advance(s.advance)
+				err := c.Send(s.key)
+				if dropped := errors.Is(err, channel.ErrDropped); dropped != s.dropped {
+					t.Fatalf("send %d of %q returned %v, want dropped %v", idx, s.key, err, s.dropped)
+				}
+			}
+		})
+	}
+}
+
+func TestDedupeDistinctKeys(t *testing.T) {
+	c, err := channel.New(1000, channel.WithStage(channel.Dedupe(func(v string) string { return v }, time.Hour)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for idx := range 1000 {
+		if err := c.Send(fmt.Sprint(idx)); err != nil {
+			t.Fatalf("distinct key %d returned %v", idx, err)
+		}
+	}
+
+	if c.Len() != 1000 {
+		t.Fatalf("buffered %d values, want 1000", c.Len())
+	}
+}
@@ -0,0 +1,127 @@
+package channel
+
+import (
+	"context"
+	"slices"
+	"sync"
+)
+
+// WeightedTarget is one destination of Distribute
+type WeightedTarget[T any] struct {
+	Ch chan<- T
+	// Weight is the share of the values the target receives, a target of weight 0 receives nothing
+	Weight int
+}
+
+// Distribute sends each value of source accepted by the middlewares in params to exactly one target,
+// picked by smooth weighted round-robin so that every target gets its share evenly spread over time.
+// When the picked target is full the value goes to the next one in line that has room,
+// when they are all full it waits for the picked one.
+// It stops when source is closed or the returned function is called, then closes every target channel.
+// The returned function waits for that to complete.
+func Distribute[T any](source <-chan T, targets []WeightedTarget[T], params ...WithOptions[T]) func() {
+	opts := newOptions(params)
+	// middlewares still running when the returned function is called see ctx done
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	finished := make(chan struct{})
+
+	picker := newWeightedPicker(targets)
+	deliver := func(data T) {
+		order := picker.next()
+		if len(order) == 0 {
+			return
+		}
+
+		for _, idx := range order {
+			select {
+			case targets[idx].Ch <- data:
+				return
+			default:
+			}
+		}
+
+		select {
+		case targets[order[0]].Ch <- data:
+		case <-done:
+		}
+	}
+
+	go func() {
+		defer close(finished)
+		defer cancel()
+		defer func() {
+			for idx := range targets {
+				close(targets[idx].Ch)
+			}
+		}()
+
+		for {
+			select {
+			case <-done:
+				return
+			case data, ok := <-source:
+				if !ok {
+					return
+				}
+
+				_ = opts.run(ctx, data, deliver)
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			close(done)
+			cancel()
+		})
+
+		<-finished
+	}
+}
+
+// weightedPicker is the smooth weighted round-robin of nginx: each pick raises every target
+// by its weight, takes the highest and lowers it by the total weight
+type weightedPicker struct {
+	weights []int
+	current []int
+	total   int
+	// reused by next, targets by decreasing current weight
+	order []int
+}
+
+func newWeightedPicker[T any](targets []WeightedTarget[T]) *weightedPicker {
+	p := &weightedPicker{
+		weights: make([]int, len(targets)),
+		current: make([]int, len(targets)),
+	}
+
+	for idx := range targets {
+		if targets[idx].Weight > 0 {
+			p.weights[idx] = targets[idx].Weight
+			p.total += targets[idx].Weight
+			p.order = append(p.order, idx)
+		}
+	}
+
+	return p
+}
+
+// next returns the targets of non zero weight, the picked one first and then the next best ones
+func (p *weightedPicker) next() []int {
+	if p.total == 0 {
+		return nil
+	}
+
+	for _, idx := range p.order {
+		p.current[idx] += p.weights[idx]
+	}
+
+	slices.SortStableFunc(p.order, func(a, b int) int {
+		return p.current[b] - p.current[a]
+	})
+
+	p.current[p.order[0]] -= p.total
+	return p.order
+}
@@ -0,0 +1,32 @@
+package channel_test
+
+import (
+	"testing"
+
+	"github.com/lamlv2305/rok/channel"
+)
+
+func TestDistributeWeights(t *testing.T) {
+	weights := []int{5, 3, 2, 0}
+	targets := make([]channel.WeightedTarget[int], len(weights))
+	for idx := range targets {
+		// large enough for every value, so no pick falls back to another target
+		targets[idx] = channel.WeightedTarget[int]{Ch: make(chan int, 10000), Weight: weights[idx]}
+	}
+
+	source := make(chan int)
+	stop := channel.Distribute(source, targets)
+	for idx := range 10000 {
+		source <- idx
+	}
+	close(source)
+	stop()
+
+	for idx := range targets {
+		got := len(targets[idx].Ch)
+		want := 10000 * weights[idx] / 10
+		if got < want-100 || got > want+100 {
+			t.Fatalf("target of weight %d got %d values, want %d±100", weights[idx], got, want)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package channel
+
+import (
+	"context"
+	"errors"
+)
+
+var (
+	// ErrDropped is reported when a boolean middleware or transform rejects a value
+	ErrDropped = errors.New("channel: value dropped by middleware")
+	// ErrPanic is reported when a middleware panicked and the panic was recovered
+	ErrPanic = errors.New("channel: middleware panicked")
+	// ErrTimeout is reported when a middleware did not return within WithTimeout
+	ErrTimeout = errors.New("channel: middleware timed out")
+	// ErrExpired is reported when a value stayed buffered longer than WithTTL allows
+	ErrExpired = errors.New("channel: value expired")
+	// ErrUnreadable is reported for a value spilled with WithSpill that the store fails to hand back
+	ErrUnreadable = errors.New("channel: spilled value unreadable")
+	// ErrFull is returned by Send when the buffer is full and the overflow policy is DropNewest
+	ErrFull = errors.New("channel: buffer full")
+	// ErrClosed is returned when using something that was already closed
+	ErrClosed = errors.New("channel: closed")
+	// ErrCircuitOpen is returned by a CircuitBreaker that is not letting calls through
+	ErrCircuitOpen = errors.New("channel: circuit open")
+	// ErrUnknownMiddleware is returned by Build for a name missing from the registry
+	ErrUnknownMiddleware = errors.New("channel: unknown middleware")
+)
+
+// StepError tells which middleware rejected a value, Step is empty for unnamed middlewares
+type StepError struct {
+	Step string
+	Err  error
+}
+
+func (e *StepError) Error() string {
+	if e.Step == "" {
+		return e.Err.Error()
+	}
+
+	return e.Step + ": " + e.Err.Error()
+}
+
+func (e *StepError) Unwrap() error {
+	return e.Err
+}
+
+type ErrMiddleware[T any] func(T) error
+
+func WithErrMiddleware[T any](middlewares ...ErrMiddleware[T]) WithOptions[T] {
+	return func(o *options[T]) {
+		for idx := range middlewares {
+			o.pipeline.add(middlewares[idx].step())
+		}
+	}
+}
+
+// WithErrorHandler is called synchronously with every rejected value and the reason,
+// before the next value is processed
+func WithErrorHandler[T any](fn func(T, error)) WithOptions[T] {
+	return func(o *options[T]) {
+		o.errorHandler = fn
+	}
+}
+
+func (m ErrMiddleware[T]) step() step[T] {
+	return step[T]{
+		fn: func(_ context.Context, data T) (T, error) {
+			return data, m(data)
+		},
+	}
+}
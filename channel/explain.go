@@ -0,0 +1,68 @@
+package channel
+
+import (
+	"context"
+	"time"
+)
+
+// StepResult is what one middleware did to the value given to Explain
+type StepResult struct {
+	// Name is empty for middlewares not wrapped with Named
+	Name     string
+	Dropped  bool
+	Err      error
+	Duration time.Duration
+	// Value is the value after the step, or before it when the step dropped it
+	Value any
+}
+
+// Explain runs data through the middlewares one by one and reports each of them, up to and including
+// the first one dropping it, which is then the last result. Nothing is delivered anywhere.
+// A splitter continues with its first part only, a panic is reported as a drop with ErrPanic.
+// It is meant for debugging and allocates freely.
+func (p *Pipeline[T]) Explain(data T) []StepResult {
+	results := make([]StepResult, 0, len(p.steps))
+	for idx := range p.steps {
+		s := p.steps[idx]
+		start := time.Now()
+
+		var err error
+		if s.split != nil {
+			data, err = explainSplit(s.split, data)
+		} else {
+			var result T
+			if result, err = invoke(context.Background(), s.fn, func(T, any) {}, data); err == nil {
+				data = result
+			}
+		}
+
+		results = append(results, StepResult{
+			Name:     s.name,
+			Dropped:  err != nil,
+			Err:      err,
+			Duration: time.Since(start),
+			Value:    data,
+		})
+
+		if err != nil {
+			break
+		}
+	}
+
+	return results
+}
+
+func explainSplit[T any](split func(T) []T, data T) (first T, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			first, err = data, ErrPanic
+		}
+	}()
+
+	parts := split(data)
+	if len(parts) == 0 {
+		return data, ErrDropped
+	}
+
+	return parts[0], nil
+}
@@ -0,0 +1,34 @@
+package channel_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/lamlv2305/rok/channel"
+)
+
+func TestExplain(t *testing.T) {
+	p := channel.NewPipeline[int]().UseStage(
+		channel.Named("double", channel.Transform[int](func(v int) (int, bool) { return v * 2, true })),
+		channel.Named("positive", channel.Middleware[int](func(v int) bool { return v > 0 })),
+		channel.Named("increment", channel.Transform[int](func(v int) (int, bool) { return v + 1, true })),
+	)
+
+	got := p.Explain(-1)
+	if len(got) != 2 {
+		t.Fatalf("got %d results, want the steps up to the one dropping", len(got))
+	}
+
+	if got[0].Name != "double" || got[0].Dropped || got[0].Err != nil || got[0].Value != -2 {
+		t.Fatalf("first step reported %+v", got[0])
+	}
+
+	if got[1].Name != "positive" || !got[1].Dropped || !errors.Is(got[1].Err, channel.ErrDropped) || got[1].Value != -2 {
+		t.Fatalf("dropping step reported %+v", got[1])
+	}
+
+	got = p.Explain(1)
+	if len(got) != 3 || got[2].Dropped || got[2].Value != 3 {
+		t.Fatalf("a value passing every step reported %+v", got)
+	}
+}
@@ -0,0 +1,166 @@
+// Package fakeclock provides a channel.Clock whose time only moves when told to,
+// so tests of time based stages need no real sleep.
+package fakeclock
+
+import (
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/lamlv2305/rok/channel"
+)
+
+// Clock starts at the time given to New and only moves with Advance.
+// Timers and tickers fire while Advance walks through their deadlines, in deadline order,
+// AfterFunc callbacks run in their own goroutine like with the time package.
+type Clock struct {
+	mu      sync.Mutex
+	now     time.Time
+	waiters []*waiter
+}
+
+type waiter struct {
+	at time.Time
+	// non zero for tickers
+	period time.Duration
+	ch     chan time.Time
+	fn     func()
+}
+
+var _ channel.Clock = (*Clock)(nil)
+
+func New(now time.Time) *Clock {
+	return &Clock{now: now}
+}
+
+func (c *Clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *Clock) After(d time.Duration) <-chan time.Time {
+	w := &waiter{ch: make(chan time.Time, 1)}
+	c.schedule(w, d)
+	return w.ch
+}
+
+// NewTicker panics when d is not positive, like time.NewTicker
+func (c *Clock) NewTicker(d time.Duration) channel.Ticker {
+	if d <= 0 {
+		panic("fakeclock: non-positive interval for NewTicker")
+	}
+
+	w := &waiter{period: d, ch: make(chan time.Time, 1)}
+	c.schedule(w, d)
+	return &ticker{clock: c, waiter: w}
+}
+
+func (c *Clock) AfterFunc(d time.Duration, f func()) channel.Timer {
+	w := &waiter{fn: f}
+	c.schedule(w, d)
+	return &timer{clock: c, waiter: w}
+}
+
+// Advance moves the time forward by d and fires everything due on the way
+func (c *Clock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	target := c.now.Add(d)
+	for len(c.waiters) > 0 && !c.waiters[0].at.After(target) {
+		w := c.waiters[0]
+		c.now = w.at
+		c.remove(w)
+		c.fire(w)
+	}
+
+	c.now = target
+}
+
+// Pending is how many timers and tickers are waiting, tests use it to know
+// a goroutine reached the point where it waits on the clock
+func (c *Clock) Pending() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.waiters)
+}
+
+func (c *Clock) schedule(w *waiter, d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	w.at = c.now.Add(d)
+	if d <= 0 {
+		c.fire(w)
+		return
+	}
+
+	c.insert(w)
+}
+
+// fire, insert and remove must be called with mu held
+func (c *Clock) fire(w *waiter) {
+	if w.fn != nil {
+		go w.fn()
+		return
+	}
+
+	// like time.Ticker, a tick nobody received yet is not queued twice
+	select {
+	case w.ch <- c.now:
+	default:
+	}
+
+	if w.period > 0 {
+		w.at = w.at.Add(w.period)
+		c.insert(w)
+	}
+}
+
+func (c *Clock) insert(w *waiter) {
+	idx := sort.Search(len(c.waiters), func(i int) bool {
+		return c.waiters[i].at.After(w.at)
+	})
+
+	c.waiters = slices.Insert(c.waiters, idx, w)
+}
+
+// remove reports whether w was still waiting
+func (c *Clock) remove(w *waiter) bool {
+	for idx := range c.waiters {
+		if c.waiters[idx] == w {
+			c.waiters = slices.Delete(c.waiters, idx, idx+1)
+			return true
+		}
+	}
+
+	return false
+}
+
+type ticker struct {
+	clock  *Clock
+	waiter *waiter
+}
+
+func (t *ticker) C() <-chan time.Time {
+	return t.waiter.ch
+}
+
+func (t *ticker) Stop() {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+	t.clock.remove(t.waiter)
+}
+
+type timer struct {
+	clock  *Clock
+	waiter *waiter
+}
+
+func (t *timer) Stop() bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+	return t.clock.remove(t.waiter)
+}
@@ -0,0 +1,91 @@
+package channel
+
+import "sync"
+
+// Target is one destination of FanOut
+type Target[T any] struct {
+	Ch          chan<- T
+	Middlewares []Middleware[T]
+	// Buffer is how many values the target may lag behind before Policy applies
+	Buffer int
+	// Policy applied when Buffer is full, Block lets a slow target hold back all the others
+	Policy OverflowPolicy
+	// Clone copies each value for this target before its middlewares run, like WithClone.
+	// Nil hands over the value read from source, shared with every other target.
+	Clone func(T) T
+}
+
+// FanOut copies every value of source to each target whose middlewares accept it.
+// It stops when source is closed or the returned function is called, then every target channel
+// is closed once the values already buffered for it are delivered. After the returned function is called
+// a target only gets the buffered values it is ready to receive right away, the rest are dropped,
+// so a target nobody reads anymore does not keep FanOut alive. The returned function waits for that to complete.
+func FanOut[T any](source <-chan T, targets ...Target[T]) func() {
+	done := make(chan struct{})
+	queues := make([]chan T, len(targets))
+	pipelines := make([]*Pipeline[T], len(targets))
+
+	var wg sync.WaitGroup
+	for idx := range targets {
+		queues[idx] = make(chan T, max(targets[idx].Buffer, 0))
+		pipelines[idx] = NewPipeline[T]().Use(targets[idx].Middlewares...)
+
+		wg.Add(1)
+		go func(queue <-chan T, target chan<- T) {
+			defer wg.Done()
+			defer close(target)
+
+			for data := range queue {
+				select {
+				case target <- data:
+				case <-done:
+					select {
+					case target <- data:
+					default:
+					}
+				}
+			}
+		}(queues[idx], targets[idx].Ch)
+	}
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer func() {
+			for idx := range queues {
+				close(queues[idx])
+			}
+		}()
+
+		for {
+			select {
+			case <-done:
+				return
+			case data, ok := <-source:
+				if !ok {
+					return
+				}
+
+				for idx := range targets {
+					value := data
+					if targets[idx].Clone != nil {
+						value = targets[idx].Clone(data)
+					}
+
+					if pipelines[idx].Run(value) {
+						offer(queues[idx], value, targets[idx].Policy, done)
+					}
+				}
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			close(done)
+		})
+
+		wg.Wait()
+	}
+}
@@ -1,47 +1,114 @@
 package channel
 
 import (
-	"github.com/google/uuid"
+	"context"
 	cmap "github.com/orcaman/concurrent-map/v2"
 	"github.com/rs/zerolog/log"
 	"sync"
+	"sync/atomic"
 )
 
 func NewFanout[T any](params ...WithOptions[T]) Fanout[T] {
-	opts := options[T]{}
-	for idx := range params {
-		params[idx](&opts)
+	opts := newOptions(params)
+	if !opts.overflowSet {
+		opts.overflow = DropNewest
 	}
 
+	life := &lifecycle{done: make(chan struct{})}
+	opts.running = &life.running
+
 	ins := Fanout[T]{
-		data:    cmap.New[*SingleData[T]](),
-		options: opts,
+		data:        cmap.New[*SingleData[T]](),
+		subscribers: &subscribers[T]{},
+		mu:          &sync.RWMutex{},
+		options:     opts,
+		life:        life,
 	}
 
 	if opts.relay > 0 {
 		ins.relay = &RelaySlice{
 			mu:   &sync.RWMutex{},
-			data: nil,
+			data: make([]any, opts.relay),
 			size: opts.relay,
 		}
 	}
 
+	if opts.workers > 0 {
+		ins.pool = newPool(opts.workers, opts.ordered, opts.orderKey, ins.process, ins.deliver)
+	}
+
+	if opts.shutdown != nil {
+		context.AfterFunc(opts.shutdown, ins.Stop)
+	}
+
 	return ins
 }
 
 type Fanout[T any] struct {
-	data    cmap.ConcurrentMap[string, *SingleData[T]]
-	relay   *RelaySlice
-	options options[T]
+	data        cmap.ConcurrentMap[string, *SingleData[T]]
+	subscribers *subscribers[T]
+	mu          *sync.RWMutex
+	relay       *RelaySlice
+	options     options[T]
+	pool        *pool[T]
+	life        *lifecycle
+}
+
+// lifecycle tracks every SendContext in flight and the goroutines it started, for Stop
+type lifecycle struct {
+	mu      sync.Mutex
+	closed  bool
+	running sync.WaitGroup
+	// closed once the first Stop returns
+	done chan struct{}
+}
+
+// subscribers is a copy of data rebuilt on every change, so publishing iterates it without allocating
+type subscribers[T any] struct {
+	mu       sync.Mutex
+	snapshot atomic.Pointer[[]*SingleData[T]]
+}
+
+func (f *Fanout[T]) refresh() {
+	f.subscribers.mu.Lock()
+	defer f.subscribers.mu.Unlock()
+
+	items := make([]*SingleData[T], 0, f.data.Count())
+	for _, v := range f.data.Items() {
+		items = append(items, v)
+	}
+
+	f.subscribers.snapshot.Store(&items)
 }
 
 type SingleData[T any] struct {
-	ch     chan T
-	closed bool
+	// held for reading while publishing to ch, Close takes it to close ch
+	mu sync.RWMutex
+	ch chan T
+	// closed on Close so a publisher blocked on a full ch gives up
+	done   chan struct{}
+	once   sync.Once
 	ignore func(T) bool
 }
 
 func (f *Fanout[T]) Send(sendingData T) {
+	_ = f.SendContext(context.Background(), sendingData)
+}
+
+// SendContext runs the pipeline with ctx, a value is dropped once ctx is done.
+// The returned error is the reason the value was not delivered, if any.
+func (f *Fanout[T]) SendContext(ctx context.Context, sendingData T) (err error) {
+	// counted under mu so that Stop, which only waits once closed, never misses a goroutine
+	f.life.mu.Lock()
+	if f.life.closed {
+		f.life.mu.Unlock()
+		return ErrClosed
+	}
+
+	f.life.running.Add(1)
+	f.life.mu.Unlock()
+	defer f.life.running.Done()
+
 	defer func() {
 		// Quick hack
 		// Do ko đc phép close khi có nhiều subscriber nhưng mà đang quick hack nên tạm thời để như này đã
@@ -52,76 +119,134 @@ func (f *Fanout[T]) Send(sendingData T) {
 
 	for idx := range f.options.sideEffect {
 		// show something like log here
-		go f.options.sideEffect[idx](sendingData)
+		f.life.running.Add(1)
+		go func(sideEffect func(T)) {
+			defer f.life.running.Done()
+			sideEffect(sendingData)
+		}(f.options.sideEffect[idx])
 	}
 
-	for idx := range f.options.middlewares {
-		if !f.options.middlewares[idx](sendingData) {
-			// filter data
-			return
-		}
+	if f.pool != nil {
+		return f.pool.submit(ctx, sendingData)
 	}
 
+	// filtered data is reported through err
+	return f.options.run(ctx, sendingData, f.publish)
+}
+
+func (f *Fanout[T]) publish(sendingData T) {
+	// a new subscriber gets the replay then every later value, nothing in between.
+	// Only that needs mu, a publisher blocked on a subscriber must not hold up Subscribe and Close.
+	f.mu.RLock()
 	if f.relay != nil {
-		go f.relay.Add(sendingData)
+		f.relay.Add(sendingData)
 	}
 
-	for m := range f.data.IterBuffered() {
-		if m.Val.closed {
-			continue
-		}
+	snapshot := f.subscribers.snapshot.Load()
+	f.mu.RUnlock()
 
-		if m.Val.ignore != nil && m.Val.ignore(sendingData) {
+	if snapshot == nil {
+		return
+	}
+
+	for _, sub := range *snapshot {
+		if sub.ignore != nil && sub.ignore(sendingData) {
 			continue
 		}
 
-		m.Val.ch <- sendingData
+		sub.send(f.options.copyOf(sendingData), f.options.overflow)
 	}
 }
 
-// Wait
-// buffer -> channel size
-// ignore -> tương tự filter bên js
-func (f *Fanout[T]) Wait(buffer int, ignore func(T) bool) (chan T, func()) {
-	ch := make(chan T, buffer)
-
-	id := uuid.New().String()
-	f.data.Set(id, &SingleData[T]{
-		ch:     ch,
-		closed: false,
-		ignore: ignore,
-	})
-
-	go func() {
-		// sending relay data
-		if f.relay == nil {
-			return
+// send is a no-op once the subscription is closed
+func (s *SingleData[T]) send(data T, policy OverflowPolicy) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	select {
+	case <-s.done:
+		return
+	default:
+	}
+
+	offer(s.ch, data, policy, s.done)
+}
+
+// process and deliver are used by the worker pool, with the same recovery as SendContext
+func (f *Fanout[T]) process(ctx context.Context, sendingData T, emit func(T)) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Error().Any("err", r).Msg("Fail to handle channel")
+			err = ErrPanic
 		}
+	}()
 
-		relayedData := f.relay.Get()
-		for idx := range relayedData {
-			if ignore != nil && ignore(relayedData[idx].(T)) {
-				continue
-			}
+	return f.options.run(ctx, sendingData, emit)
+}
 
-			ch <- relayedData[idx].(T)
+func (f *Fanout[T]) deliver(sendingData T) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Error().Any("err", r).Msg("Fail to handle channel")
 		}
 	}()
 
-	return ch, func() {
-		f.data.RemoveCb(id, func(key string, v *SingleData[T], exists bool) bool {
-			defer func() {
-				if r := recover(); r != nil {
-					log.Error().Any("request", r).Msg("[CRITICAL] Fail to handling close fanout")
-				}
-			}()
-
-			if exists && v != nil && !v.closed {
-				v.closed = true
-				close(v.ch)
+	f.publish(sendingData)
+}
+
+// Stop refuses new values with ErrClosed and returns once every value already sent went through
+// the middlewares and was delivered, along with the side effects and WithTimeout calls it started.
+// Subscriptions stay open, close them to release their readers.
+// A middleware that ignores its context and never returns after a timeout keeps Stop blocked.
+func (f *Fanout[T]) Stop() {
+	f.life.mu.Lock()
+	first := !f.life.closed
+	f.life.closed = true
+	f.life.mu.Unlock()
+
+	if f.pool != nil {
+		f.pool.stop()
+	}
+
+	f.life.running.Wait()
+	if first {
+		close(f.life.done)
+	}
+}
+
+// Done is closed once Stop returned, including the Stop triggered by the context of WithContext
+func (f *Fanout[T]) Done() <-chan struct{} {
+	return f.life.done
+}
+
+// Run sends every value read from in until in is closed or ctx is done
+func (f *Fanout[T]) Run(ctx context.Context, in <-chan T) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case data, ok := <-in:
+			if !ok {
+				return nil
 			}
 
-			return true
-		})
+			if err := f.SendContext(ctx, data); err != nil && ctx.Err() != nil {
+				return ctx.Err()
+			}
+		}
 	}
 }
+
+// Stats returns the counters of every named middleware, unnamed ones are grouped under "anonymous".
+// It is nil without WithStats.
+func (f *Fanout[T]) Stats() map[string]MiddlewareStat {
+	return f.options.stats.snapshot()
+}
+
+// Wait
+// buffer -> channel size
+// ignore -> tương tự filter bên js
+func (f *Fanout[T]) Wait(buffer int, ignore func(T) bool) (chan T, func()) {
+	sub := f.Subscribe(buffer, ignore)
+	return sub.ch, sub.Close
+}
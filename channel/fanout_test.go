@@ -0,0 +1,145 @@
+package channel
+
+import (
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestFanoutStalledSubscriberDoesNotBlock(t *testing.T) {
+	f := NewFanout[int]()
+	stalled := f.Subscribe(1, nil)
+	defer stalled.Close()
+
+	live := f.Subscribe(100, nil)
+	defer live.Close()
+
+	within(t, time.Second, func() {
+		for idx := range 100 {
+			f.Send(idx)
+		}
+	})
+
+	if got := len(live.C()); got != 100 {
+		t.Fatalf("live subscriber got %d values, want 100", got)
+	}
+
+	if got := len(stalled.C()); got != 1 {
+		t.Fatalf("stalled subscriber got %d values, want its buffer of 1", got)
+	}
+}
+
+func TestFanoutSubscriptionChurn(t *testing.T) {
+	policies := []struct {
+		name   string
+		policy OverflowPolicy
+	}{
+		{name: "block", policy: Block},
+		{name: "drop newest", policy: DropNewest},
+		{name: "drop oldest", policy: DropOldest},
+	}
+
+	for _, tt := range policies {
+		t.Run(tt.name, func(t *testing.T) {
+			f := NewFanout(WithOverflow[int](tt.policy))
+			stop := make(chan struct{})
+
+			var publishers sync.WaitGroup
+			for range 2 {
+				publishers.Add(1)
+				go func() {
+					defer publishers.Done()
+					for idx := 0; ; idx++ {
+						select {
+						case <-stop:
+							return
+						default:
+							f.Send(idx)
+							runtime.Gosched()
+						}
+					}
+				}()
+			}
+
+			within(t, 5*time.Second, func() {
+				var subscribers sync.WaitGroup
+				for idx := range 100 {
+					subscribers.Add(1)
+					go func() {
+						defer subscribers.Done()
+
+						sub := f.Subscribe(idx%3, nil)
+						// some read a little, the others never do
+						if idx%2 == 0 {
+							for range 3 {
+								<-sub.C()
+							}
+						}
+
+						sub.Close()
+						for range sub.C() {
+						}
+					}()
+				}
+
+				subscribers.Wait()
+			})
+
+			close(stop)
+			publishers.Wait()
+		})
+	}
+}
+
+func TestFanOutStopWithUnreadTarget(t *testing.T) {
+	source := make(chan int)
+	read, unread := make(chan int, 10), make(chan int)
+	stop := FanOut(source,
+		Target[int]{Ch: read, Buffer: 10},
+		Target[int]{Ch: unread, Buffer: 10},
+	)
+
+	for idx := range 5 {
+		source <- idx
+	}
+
+	within(t, time.Second, stop)
+
+	var got []int
+	for v := range read {
+		got = append(got, v)
+	}
+
+	// values may still be queued when stop is called, what was delivered is in order
+	for idx := range got {
+		if got[idx] != idx {
+			t.Fatalf("read target got %v, want a prefix of 0..4", got)
+		}
+	}
+
+	if _, ok := <-unread; ok {
+		t.Fatal("unread target is still open")
+	}
+}
+
+func TestFanOutDeliversBufferedOnSourceClose(t *testing.T) {
+	source := make(chan int)
+	target := make(chan int)
+	stop := FanOut(source, Target[int]{Ch: target, Buffer: 10})
+
+	for idx := range 5 {
+		source <- idx
+	}
+	close(source)
+
+	var got []int
+	for v := range target {
+		got = append(got, v)
+	}
+
+	within(t, time.Second, stop)
+	if len(got) != 5 {
+		t.Fatalf("target got %v, want every value once source is closed", got)
+	}
+}
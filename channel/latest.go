@@ -0,0 +1,119 @@
+package channel
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// defaultInterval is the cadence of Latest without WithInterval
+const defaultInterval = time.Second
+
+// WithInterval sets how often Latest emits, one second when d is not positive
+func WithInterval[T any](d time.Duration) WithOptions[T] {
+	return func(o *options[T]) {
+		o.interval = d
+	}
+}
+
+// LatestStage keeps only the last value passing the middlewares and emits it on Out once per interval.
+// Unlike Debouncer it emits on a fixed cadence even under sustained load, an interval without any
+// new value emits nothing.
+type LatestStage[T any] struct {
+	mu      sync.Mutex
+	latest  T
+	pending bool
+	out     chan T
+	quit    chan struct{}
+	done    chan struct{}
+	closed  bool
+	options options[T]
+}
+
+func Latest[T any](params ...WithOptions[T]) *LatestStage[T] {
+	opts := newOptions(params)
+
+	interval := opts.interval
+	if interval <= 0 {
+		interval = defaultInterval
+	}
+
+	ticker := opts.clock.NewTicker(interval)
+
+	l := &LatestStage[T]{
+		out:     make(chan T),
+		quit:    make(chan struct{}),
+		done:    make(chan struct{}),
+		options: opts,
+	}
+
+	go func() {
+		defer close(l.done)
+		defer close(l.out)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C():
+				l.flush()
+			case <-l.quit:
+				l.flush()
+				return
+			}
+		}
+	}()
+
+	return l
+}
+
+func (l *LatestStage[T]) Out() <-chan T {
+	return l.out
+}
+
+// Send runs the middlewares on data and makes it the value emitted at the next tick,
+// values sent after Close are ignored
+func (l *LatestStage[T]) Send(data T) {
+	_ = l.options.run(context.Background(), data, l.set)
+}
+
+func (l *LatestStage[T]) set(data T) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.closed {
+		return
+	}
+
+	l.latest = data
+	l.pending = true
+}
+
+// Close emits the pending value, if any, and closes Out once it is consumed
+func (l *LatestStage[T]) Close() {
+	l.mu.Lock()
+	if l.closed {
+		l.mu.Unlock()
+		return
+	}
+
+	l.closed = true
+	l.mu.Unlock()
+
+	close(l.quit)
+	<-l.done
+}
+
+func (l *LatestStage[T]) flush() {
+	l.mu.Lock()
+	if !l.pending {
+		l.mu.Unlock()
+		return
+	}
+
+	var zero T
+	data := l.latest
+	l.latest, l.pending = zero, false
+	l.mu.Unlock()
+
+	l.out <- data
+}
@@ -0,0 +1,36 @@
+package channel_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/lamlv2305/rok/channel"
+	"github.com/lamlv2305/rok/channel/fakeclock"
+)
+
+func TestLatestKeepsLastValue(t *testing.T) {
+	clock := fakeclock.New(time.Unix(0, 0))
+	latest := channel.Latest(channel.WithInterval[int](time.Second), channel.WithClock[int](clock))
+
+	for idx := range 100 {
+		latest.Send(idx)
+	}
+
+	clock.Advance(time.Second)
+	if got := <-latest.Out(); got != 99 {
+		t.Fatalf("emitted %d, want the last value 99", got)
+	}
+
+	// an interval without any new value emits nothing
+	clock.Advance(time.Second)
+	latest.Close()
+
+	var rest []int
+	for v := range latest.Out() {
+		rest = append(rest, v)
+	}
+
+	if len(rest) != 0 {
+		t.Fatalf("emitted %v after the first tick, want a single value per interval", rest)
+	}
+}
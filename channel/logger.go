@@ -0,0 +1,117 @@
+package channel
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// WithLogger logs every middleware decision at debug level with the middleware name,
+// whether it dropped the value and how long it took. Nothing is logged without it.
+func WithLogger[T any](logger *slog.Logger) WithOptions[T] {
+	return func(o *options[T]) {
+		o.logger = logger
+	}
+}
+
+// WithDropAlert logs a warning when more than threshold (0 to 1) of the last window values
+// were dropped, once per crossing: it warns again only after the rate went back under threshold.
+// It uses the WithLogger logger, or slog.Default.
+func WithDropAlert[T any](window int, threshold float64) WithOptions[T] {
+	return func(o *options[T]) {
+		o.dropAlert = &dropAlert{
+			outcomes:  make([]bool, max(window, 1)),
+			threshold: threshold,
+		}
+	}
+}
+
+// observe records one middleware call into stats and the logger
+func (o *options[T]) observe(ctx context.Context, idx int, err error, elapsed time.Duration) {
+	if o.stats != nil {
+		o.stats.slots[idx].record(err != nil, elapsed)
+	}
+
+	if o.logger == nil || !o.logger.Enabled(ctx, slog.LevelDebug) {
+		return
+	}
+
+	name := o.pipeline.steps[idx].name
+	if name == "" {
+		name = anonymous
+	}
+
+	attrs := []slog.Attr{
+		slog.String("channel", o.name),
+		slog.String("middleware", name),
+		slog.Bool("dropped", err != nil),
+		slog.Duration("duration", elapsed),
+	}
+
+	if err != nil {
+		attrs = append(attrs, slog.Any("error", err))
+	}
+
+	o.logger.LogAttrs(ctx, slog.LevelDebug, "channel middleware", attrs...)
+}
+
+func (o *options[T]) alert(ctx context.Context, dropped bool) {
+	rate, crossed := o.dropAlert.record(dropped)
+	if !crossed {
+		return
+	}
+
+	logger := o.logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	logger.LogAttrs(ctx, slog.LevelWarn, "channel drop rate above threshold",
+		slog.String("channel", o.name),
+		slog.Float64("rate", rate),
+		slog.Float64("threshold", o.dropAlert.threshold),
+		slog.Int("window", len(o.dropAlert.outcomes)),
+	)
+}
+
+// dropAlert is a ring of the last outcomes, true for dropped
+type dropAlert struct {
+	mu        sync.Mutex
+	outcomes  []bool
+	next      int
+	count     int
+	drops     int
+	threshold float64
+	alerting  bool
+}
+
+// record reports the drop rate and whether it just went above threshold
+func (a *dropAlert) record(dropped bool) (float64, bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.count == len(a.outcomes) {
+		if a.outcomes[a.next] {
+			a.drops--
+		}
+	} else {
+		a.count++
+	}
+
+	a.outcomes[a.next] = dropped
+	a.next = (a.next + 1) % len(a.outcomes)
+	if dropped {
+		a.drops++
+	}
+
+	rate := float64(a.drops) / float64(a.count)
+	if rate <= a.threshold {
+		a.alerting = false
+		return rate, false
+	}
+
+	crossed := !a.alerting
+	a.alerting = true
+	return rate, crossed
+}
@@ -0,0 +1,93 @@
+package channel_test
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"testing"
+
+	"github.com/lamlv2305/rok/channel"
+)
+
+// recorder is a slog.Handler keeping every record
+type recorder struct {
+	mu      sync.Mutex
+	records []slog.Record
+}
+
+func (r *recorder) Enabled(context.Context, slog.Level) bool { return true }
+
+func (r *recorder) Handle(_ context.Context, record slog.Record) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.records = append(r.records, record)
+	return nil
+}
+
+func (r *recorder) WithAttrs([]slog.Attr) slog.Handler { return r }
+func (r *recorder) WithGroup(string) slog.Handler      { return r }
+
+func attrs(record slog.Record) map[string]slog.Value {
+	values := map[string]slog.Value{}
+	record.Attrs(func(attr slog.Attr) bool {
+		values[attr.Key] = attr.Value
+		return true
+	})
+
+	return values
+}
+
+func TestLogger(t *testing.T) {
+	handler := &recorder{}
+	_, ok := channel.Process(-1,
+		channel.WithName[int]("orders"),
+		channel.WithLogger[int](slog.New(handler)),
+		channel.WithStage(channel.Named("positive", channel.Middleware[int](func(v int) bool { return v > 0 }))),
+	)
+	if ok {
+		t.Fatal("value passed")
+	}
+
+	if len(handler.records) != 1 {
+		t.Fatalf("logged %d records, want 1", len(handler.records))
+	}
+
+	record := handler.records[0]
+	values := attrs(record)
+	if record.Level != slog.LevelDebug || values["channel"].String() != "orders" ||
+		values["middleware"].String() != "positive" || !values["dropped"].Bool() {
+		t.Fatalf("logged %s %v", record.Level, values)
+	}
+
+	if _, ok := values["duration"]; !ok {
+		t.Fatalf("logged %v without a duration", values)
+	}
+}
+
+func TestDropAlert(t *testing.T) {
+	handler := &recorder{}
+	c, err := channel.New(16,
+		channel.WithLogger[int](slog.New(handler)),
+		channel.WithDropAlert[int](4, 0.5),
+		channel.WithMiddleware(func(v int) bool { return v > 0 }),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the rate goes above 0.5, back under it, then above again
+	for _, v := range []int{1, -1, -1, -1, 1, 1, 1, 1, -1, -1, -1} {
+		_ = c.Send(v)
+	}
+
+	warnings := 0
+	for _, record := range handler.records {
+		if record.Level == slog.LevelWarn {
+			warnings++
+		}
+	}
+
+	if warnings != 2 {
+		t.Fatalf("logged %d warnings, want one per crossing, 2", warnings)
+	}
+}
@@ -0,0 +1,127 @@
+package channel
+
+import (
+	"context"
+	"sync"
+)
+
+// Merger merges several sources into a single output and runs the middlewares once on the merged path.
+// Sources are served round-robin: every source has at most one value waiting to be merged,
+// so a fast source has to wait for its turn behind the slow ones that are ready.
+// The output is closed once every added source is closed, or on Close when none is left.
+type Merger[T any] struct {
+	mu      sync.Mutex
+	out     chan T
+	notify  chan struct{}
+	pending []pendingValue[T]
+	active  int
+	// set by Close, no source can be added anymore
+	stopped bool
+	closed  bool
+	options options[T]
+}
+
+type pendingValue[T any] struct {
+	data   T
+	resume chan struct{}
+}
+
+func NewMerger[T any](params ...WithOptions[T]) *Merger[T] {
+	m := &Merger[T]{
+		out:     make(chan T),
+		notify:  make(chan struct{}, 1),
+		options: newOptions(params),
+	}
+
+	go m.loop()
+	return m
+}
+
+func (m *Merger[T]) Out() <-chan T {
+	return m.out
+}
+
+// Add registers a new source, it fails with ErrClosed once the output was closed or Close was called
+func (m *Merger[T]) Add(source <-chan T) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.closed || m.stopped {
+		return ErrClosed
+	}
+
+	m.active++
+	go m.read(source)
+	return nil
+}
+
+// Close refuses new sources, the output is closed once the sources already added are,
+// right away when there are none. Calling it again does nothing.
+func (m *Merger[T]) Close() {
+	m.mu.Lock()
+	m.stopped = true
+	if m.active == 0 {
+		m.closed = true
+	}
+	m.mu.Unlock()
+	m.wake()
+}
+
+func (m *Merger[T]) read(source <-chan T) {
+	resume := make(chan struct{}, 1)
+	for data := range source {
+		m.mu.Lock()
+		m.pending = append(m.pending, pendingValue[T]{data: data, resume: resume})
+		m.mu.Unlock()
+		m.wake()
+
+		// wait until this value is merged before queuing the next one
+		<-resume
+	}
+
+	m.mu.Lock()
+	m.active--
+	if m.active == 0 {
+		m.closed = true
+	}
+	m.mu.Unlock()
+	m.wake()
+}
+
+func (m *Merger[T]) emit(data T) {
+	m.out <- data
+}
+
+func (m *Merger[T]) wake() {
+	select {
+	case m.notify <- struct{}{}:
+	default:
+	}
+}
+
+func (m *Merger[T]) loop() {
+	for range m.notify {
+		for {
+			m.mu.Lock()
+			if len(m.pending) == 0 {
+				closed := m.closed
+				m.mu.Unlock()
+
+				if closed {
+					close(m.out)
+					return
+				}
+
+				break
+			}
+
+			next := m.pending[0]
+			m.pending = m.pending[1:]
+			m.mu.Unlock()
+
+			_ = m.options.run(context.Background(), next.data, m.emit)
+
+			next.resume <- struct{}{}
+		}
+	}
+}
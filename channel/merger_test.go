@@ -0,0 +1,85 @@
+package channel_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/lamlv2305/rok/channel"
+)
+
+func TestMergerFairness(t *testing.T) {
+	fast, slow := make(chan int, 100), make(chan int, 5)
+	for idx := range 100 {
+		fast <- idx
+	}
+	for idx := range 5 {
+		slow <- -idx - 1
+	}
+	close(fast)
+	close(slow)
+
+	m := channel.NewMerger[int]()
+	if err := m.Add(fast); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Add(slow); err != nil {
+		t.Fatal(err)
+	}
+
+	var got []int
+	lastSlow := -1
+	for v := range m.Out() {
+		if v < 0 {
+			lastSlow = len(got)
+		}
+		got = append(got, v)
+	}
+
+	if len(got) != 105 {
+		t.Fatalf("merged %d values, want 105 before the output closed", len(got))
+	}
+
+	// served in turn, the slow source does not wait for the 100 values of the fast one
+	if lastSlow > 15 {
+		t.Fatalf("last value of the slow source merged at %d, want it among the first ones", lastSlow)
+	}
+
+	if err := m.Add(make(chan int)); !errors.Is(err, channel.ErrClosed) {
+		t.Fatalf("Add once the output closed returned %v, want ErrClosed", err)
+	}
+}
+
+func TestMergerClose(t *testing.T) {
+	m := channel.NewMerger[int]()
+	m.Close()
+
+	if _, ok := <-m.Out(); ok {
+		t.Fatal("output of a closed merger without sources is open")
+	}
+
+	m = channel.NewMerger[int]()
+	source := make(chan int)
+	if err := m.Add(source); err != nil {
+		t.Fatal(err)
+	}
+
+	m.Close()
+	if err := m.Add(make(chan int)); !errors.Is(err, channel.ErrClosed) {
+		t.Fatalf("Add after Close returned %v, want ErrClosed", err)
+	}
+
+	// sources added before Close are still merged
+	go func() {
+		source <- 1
+		close(source)
+	}()
+
+	var got []int
+	for v := range m.Out() {
+		got = append(got, v)
+	}
+
+	if len(got) != 1 || got[0] != 1 {
+		t.Fatalf("merged %v, want [1]", got)
+	}
+}
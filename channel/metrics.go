@@ -0,0 +1,25 @@
+package channel
+
+import "time"
+
+// Metrics receives one event per value going through the pipeline, name is the one set by WithName.
+// channel/metrics provides a Prometheus implementation.
+type Metrics interface {
+	In(name string)
+	Passed(name string)
+	Dropped(name string)
+	Observe(name string, elapsed time.Duration)
+}
+
+func WithMetrics[T any](metrics Metrics) WithOptions[T] {
+	return func(o *options[T]) {
+		o.metrics = metrics
+	}
+}
+
+// WithName names the channel, it is used as label by Metrics
+func WithName[T any](name string) WithOptions[T] {
+	return func(o *options[T]) {
+		o.name = name
+	}
+}
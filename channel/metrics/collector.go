@@ -0,0 +1,81 @@
+// Package metrics exports channel pipeline metrics to Prometheus.
+//
+//	collector := metrics.NewCollector("app")
+//	prometheus.MustRegister(collector)
+//
+//	fanout := channel.NewFanout[Order](
+//		channel.WithName[Order]("orders"),
+//		channel.WithMetrics[Order](collector),
+//		channel.WithMiddleware(validate),
+//	)
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"time"
+)
+
+const label = "channel"
+
+// Collector implements both channel.Metrics and prometheus.Collector,
+// one Collector can be shared by several channels told apart by their name
+type Collector struct {
+	in      *prometheus.CounterVec
+	passed  *prometheus.CounterVec
+	dropped *prometheus.CounterVec
+	latency *prometheus.HistogramVec
+}
+
+func NewCollector(namespace string) *Collector {
+	counter := func(name, help string) *prometheus.CounterVec {
+		return prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "channel",
+			Name:      name,
+			Help:      help,
+		}, []string{label})
+	}
+
+	return &Collector{
+		in:      counter("values_in_total", "Values entering the pipeline."),
+		passed:  counter("values_passed_total", "Values accepted by every middleware."),
+		dropped: counter("values_dropped_total", "Values rejected by a middleware."),
+		latency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "channel",
+			Name:      "pipeline_duration_seconds",
+			Help:      "Time spent running the middlewares of a value.",
+			Buckets:   prometheus.ExponentialBuckets(0.00001, 4, 10),
+		}, []string{label}),
+	}
+}
+
+func (c *Collector) In(name string) {
+	c.in.WithLabelValues(name).Inc()
+}
+
+func (c *Collector) Passed(name string) {
+	c.passed.WithLabelValues(name).Inc()
+}
+
+func (c *Collector) Dropped(name string) {
+	c.dropped.WithLabelValues(name).Inc()
+}
+
+func (c *Collector) Observe(name string, elapsed time.Duration) {
+	c.latency.WithLabelValues(name).Observe(elapsed.Seconds())
+}
+
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	c.in.Describe(ch)
+	c.passed.Describe(ch)
+	c.dropped.Describe(ch)
+	c.latency.Describe(ch)
+}
+
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	c.in.Collect(ch)
+	c.passed.Collect(ch)
+	c.dropped.Collect(ch)
+	c.latency.Collect(ch)
+}
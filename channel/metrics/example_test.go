@@ -0,0 +1,43 @@
+package metrics_test
+
+import (
+	"fmt"
+
+	"github.com/lamlv2305/rok/channel"
+	"github.com/lamlv2305/rok/channel/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func Example() {
+	collector := metrics.NewCollector("app")
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(collector)
+
+	fanout := channel.NewFanout[int](
+		channel.WithName[int]("orders"),
+		channel.WithMetrics[int](collector),
+		channel.WithMiddleware(func(v int) bool { return v > 0 }),
+	)
+
+	for _, v := range []int{1, -1, 2} {
+		fanout.Send(v)
+	}
+
+	families, err := registry.Gather()
+	if err != nil {
+		panic(err)
+	}
+
+	for _, family := range families {
+		for _, metric := range family.GetMetric() {
+			if counter := metric.GetCounter(); counter != nil {
+				fmt.Println(family.GetName(), metric.GetLabel()[0].GetValue(), counter.GetValue())
+			}
+		}
+	}
+
+	// Output:
+	// app_channel_values_dropped_total orders 1
+	// app_channel_values_in_total orders 3
+	// app_channel_values_passed_total orders 2
+}
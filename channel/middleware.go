@@ -1,11 +1,36 @@
 package channel
 
+import "context"
+
 type Middleware[T any] func(T) bool
 
 func WithMiddleware[T any](middlewares ...Middleware[T]) func(*options[T]) {
 	return func(o *options[T]) {
 		for idx := range middlewares {
-			o.middlewares = append(o.middlewares, middlewares[idx])
+			o.pipeline.add(middlewares[idx].step())
 		}
 	}
 }
+
+func (m Middleware[T]) step() step[T] {
+	return step[T]{
+		fn: func(_ context.Context, data T) (T, error) {
+			if !m(data) {
+				return data, ErrDropped
+			}
+
+			return data, nil
+		},
+	}
+}
+
+// WithPriorityMiddleware registers m to run before every middleware of a higher priority,
+// whatever the registration order. Middlewares registered without a priority have priority 0,
+// equal priorities run in registration order.
+func WithPriorityMiddleware[T any](priority int, m Middleware[T]) WithOptions[T] {
+	return func(o *options[T]) {
+		s := m.step()
+		s.priority = priority
+		o.pipeline.add(s)
+	}
+}
@@ -0,0 +1,39 @@
+package channel_test
+
+import (
+	"testing"
+
+	"github.com/lamlv2305/rok/channel"
+)
+
+func TestPriorityMiddleware(t *testing.T) {
+	var ran []string
+	record := func(name string) channel.Middleware[int] {
+		return func(int) bool {
+			ran = append(ran, name)
+			return true
+		}
+	}
+
+	_, ok := channel.Process(1,
+		channel.WithMiddleware(record("default")),
+		channel.WithPriorityMiddleware(10, record("late")),
+		channel.WithPriorityMiddleware(-10, record("auth")),
+		channel.WithMiddleware(record("default again")),
+		channel.WithPriorityMiddleware(-10, record("auth again")),
+	)
+	if !ok {
+		t.Fatal("value dropped")
+	}
+
+	want := []string{"auth", "auth again", "default", "default again", "late"}
+	if len(ran) != len(want) {
+		t.Fatalf("ran %v, want %v", ran, want)
+	}
+
+	for idx := range want {
+		if ran[idx] != want[idx] {
+			t.Fatalf("ran %v, want %v", ran, want)
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package channel
+
+import (
+	"errors"
+	"sync/atomic"
+	"time"
+)
+
+const anonymous = "anonymous"
+
+// Stage is a middleware of any kind: Middleware, Transform, ErrMiddleware, CtxMiddleware,
+// CtxErrMiddleware, Splitter, or what Named, When, Unless, All, Any and the helpers of this
+// package build from them. A func literal must be converted to one of the kinds first.
+//
+// Every helper of this package, RateLimit, Dedupe, Retry, CircuitBreaker, SampleEveryN and the
+// others, takes and returns stages, registered with WithStage or Pipeline.UseStage.
+// WithMiddleware and WithErrMiddleware only take plain functions.
+type Stage[T any] interface {
+	step() step[T]
+}
+
+// WithStage registers stages of any kind, in order
+func WithStage[T any](stages ...Stage[T]) WithOptions[T] {
+	return func(o *options[T]) {
+		for idx := range stages {
+			o.pipeline.add(stages[idx].step())
+		}
+	}
+}
+
+// stageFunc builds the step when the stage is registered
+type stageFunc[T any] func() step[T]
+
+func (f stageFunc[T]) step() step[T] {
+	return f()
+}
+
+// Named attaches a name to a stage of any kind, it is used to attribute Stats, drops, logs and spans.
+// The name is carried by the returned stage itself, wrapping it again with When, Unless, All or Any
+// keeps it.
+//
+//	channel.New[Event](16, channel.WithStage(channel.Named("dedupe", channel.Middleware[Event](dedupe))))
+func Named[T any](name string, stage Stage[T]) Stage[T] {
+	return stageFunc[T](func() step[T] {
+		s := stage.step()
+		s.name = name
+		return s
+	})
+}
+
+// stepError attributes err to the step named name, unless a composite stage already
+// attributed it to one of its parts
+func stepError(name string, err error) error {
+	var attributed *StepError
+	if errors.As(err, &attributed) {
+		return err
+	}
+
+	return &StepError{Step: name, Err: err}
+}
+
+type MiddlewareStat struct {
+	Invocations uint64
+	Drops       uint64
+	Duration    time.Duration
+}
+
+type stat struct {
+	invocations atomic.Uint64
+	drops       atomic.Uint64
+	nanos       atomic.Int64
+}
+
+func (s *stat) record(dropped bool, elapsed time.Duration) {
+	s.invocations.Add(1)
+	if dropped {
+		s.drops.Add(1)
+	}
+	s.nanos.Add(int64(elapsed))
+}
+
+// WithStats counts the calls, drops and time spent of every middleware, read them with Stats.
+// It is off by default since timing each call costs two clock reads per middleware.
+func WithStats[T any]() WithOptions[T] {
+	return func(o *options[T]) {
+		o.collectStats = true
+	}
+}
+
+// stats holds one counter per step name, slots is aligned with the pipeline steps
+type stats struct {
+	byName map[string]*stat
+	slots  []*stat
+}
+
+func newStats[T any](steps []step[T]) *stats {
+	s := &stats{
+		byName: map[string]*stat{},
+		slots:  make([]*stat, len(steps)),
+	}
+
+	for idx := range steps {
+		name := steps[idx].name
+		if name == "" {
+			name = anonymous
+		}
+
+		if _, ok := s.byName[name]; !ok {
+			s.byName[name] = &stat{}
+		}
+
+		s.slots[idx] = s.byName[name]
+	}
+
+	return s
+}
+
+func (s *stats) snapshot() map[string]MiddlewareStat {
+	if s == nil {
+		return nil
+	}
+
+	result := make(map[string]MiddlewareStat, len(s.byName))
+	for name, v := range s.byName {
+		result[name] = MiddlewareStat{
+			Invocations: v.invocations.Load(),
+			Drops:       v.drops.Load(),
+			Duration:    time.Duration(v.nanos.Load()),
+		}
+	}
+
+	return result
+}
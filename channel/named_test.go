@@ -0,0 +1,40 @@
+package channel_test
+
+import (
+	"testing"
+
+	"github.com/lamlv2305/rok/channel"
+)
+
+func TestStats(t *testing.T) {
+	even := channel.Named("even", channel.Middleware[int](func(v int) bool { return v%2 == 0 }))
+	positive := channel.Middleware[int](func(v int) bool { return v > 0 })
+
+	off, err := channel.New(8, channel.WithStage(even, positive))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_ = off.Send(2)
+	if stats := off.Stats(); stats != nil {
+		t.Fatalf("Stats without WithStats returned %v, want nil", stats)
+	}
+
+	c, err := channel.New(8, channel.WithStage(even, positive), channel.WithStats[int]())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, v := range []int{2, 3, -2, 4} {
+		_ = c.Send(v)
+	}
+
+	stats := c.Stats()
+	if got := stats["even"]; got.Invocations != 4 || got.Drops != 1 {
+		t.Fatalf("even: %+v, want 4 invocations and 1 drop", got)
+	}
+
+	if got := stats["anonymous"]; got.Invocations != 3 || got.Drops != 1 {
+		t.Fatalf("anonymous: %+v, want 3 invocations and 1 drop", got)
+	}
+}
@@ -1,9 +1,356 @@
 package channel
 
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
 type WithOptions[T any] func(*options[T])
 
 type options[T any] struct {
-	middlewares []Middleware[T]
-	sideEffect  []func(T)
-	relay       int
+	pipeline     Pipeline[T]
+	sideEffect   []func(T)
+	relay        int
+	errorHandler func(T, error)
+	runAll       bool
+	panicHandler func(T, any)
+	stats        *stats
+	collectStats bool
+	workers      int
+	ordered      bool
+	orderKey     func(T) any
+
+	deadLetter       chan T
+	deadLetterPolicy OverflowPolicy
+
+	name    string
+	metrics Metrics
+	tracer  Tracer
+
+	timeout time.Duration
+
+	overflow OverflowPolicy
+	// false until WithOverflow, for the owners whose default is not Block
+	overflowSet bool
+	priority    func(T) int
+
+	logger    *slog.Logger
+	dropAlert *dropAlert
+
+	ttl      time.Duration
+	ttlStart func(T) time.Time
+
+	highWater   float64
+	onHighWater func()
+	lowWater    float64
+	onLowWater  func()
+
+	interval time.Duration
+
+	clock Clock
+
+	spill SpillStore[T]
+	clone func(T) T
+
+	shutdown context.Context
+	// set by the owner to track the goroutines started on its behalf
+	running *sync.WaitGroup
+}
+
+func newOptions[T any](params []WithOptions[T]) options[T] {
+	opts := applyOptions(params)
+	if opts.collectStats {
+		opts.stats = newStats(opts.pipeline.steps)
+	}
+
+	return opts
+}
+
+// applyOptions is newOptions without stats, for the helpers only reading a few options
+func applyOptions[T any](params []WithOptions[T]) options[T] {
+	opts := options[T]{}
+	for idx := range params {
+		params[idx](&opts)
+	}
+
+	if opts.clock == nil {
+		opts.clock = systemClock{}
+		return opts
+	}
+
+	for idx := range opts.pipeline.steps {
+		if bind := opts.pipeline.steps[idx].clock; bind != nil {
+			bind(opts.clock)
+		}
+	}
+
+	return opts
+}
+
+// run passes data through every step and calls emit with each surviving value, a splitter
+// can turn one value into several. It returns nil when at least one value was emitted.
+// It stops as soon as ctx is done, the value is then dropped with ctx.Err().
+func (o *options[T]) run(ctx context.Context, data T, emit func(T)) error {
+	if o.pipeline.splitters == 0 {
+		result, err := o.runValue(ctx, data)
+		if err == nil {
+			emit(result)
+		}
+
+		return err
+	}
+
+	ctx, span, start := o.begin(ctx)
+	err := o.runFrom(ctx, span, 0, data, emit, nil)
+	o.end(ctx, span, start, err)
+	return err
+}
+
+// runValue is run for pipelines without splitters, the surviving value is returned
+// instead of emitted so callers need no callback
+func (o *options[T]) runValue(ctx context.Context, data T) (T, error) {
+	// nothing observes the pipeline as a whole, short pipelines skip the generic loop
+	if o.metrics == nil && o.tracer == nil && o.dropAlert == nil {
+		switch len(o.pipeline.steps) {
+		case 0:
+			return data, nil
+		case 1:
+			return o.single(ctx, data)
+		}
+	}
+
+	ctx, span, start := o.begin(ctx)
+	data, _, err := o.steps(ctx, span, 0, data, nil)
+	if err != nil {
+		o.drop(ctx, data, err)
+	}
+
+	o.end(ctx, span, start, err)
+	return data, err
+}
+
+// single is steps for a pipeline of exactly one step
+func (o *options[T]) single(ctx context.Context, data T) (T, error) {
+	err := ctx.Err()
+	if err == nil {
+		var result T
+		if result, err = o.call(ctx, 0, data); err == nil {
+			return result, nil
+		}
+
+		err = stepError(o.pipeline.steps[0].name, err)
+	}
+
+	o.drop(ctx, data, err)
+	return data, err
+}
+
+// begin and end wrap one value with metrics, tracing and the drop alert
+func (o *options[T]) begin(ctx context.Context) (context.Context, Span, time.Time) {
+	var start time.Time
+	if o.metrics != nil {
+		o.metrics.In(o.name)
+		start = o.clock.Now()
+	}
+
+	var span Span
+	if o.tracer != nil {
+		ctx, span = o.tracer.Start(ctx, o.name)
+	}
+
+	return ctx, span, start
+}
+
+func (o *options[T]) end(ctx context.Context, span Span, start time.Time, err error) {
+	if o.dropAlert != nil {
+		o.alert(ctx, err != nil)
+	}
+
+	if o.metrics != nil {
+		o.metrics.Observe(o.name, o.clock.Now().Sub(start))
+		if err != nil {
+			o.metrics.Dropped(o.name)
+		} else {
+			o.metrics.Passed(o.name)
+		}
+	}
+
+	if span != nil {
+		span.End(err)
+	}
+}
+
+// runFrom runs the steps starting at from and emits what survives them. rejected is set in run-all
+// mode when a step before from already rejected the value, which then only goes through the later steps
+// for their side effects and is reported by the caller.
+func (o *options[T]) runFrom(ctx context.Context, span Span, from int, data T, emit func(T), rejected error) error {
+	data, at, err := o.steps(ctx, span, from, data, rejected)
+	if at < len(o.pipeline.steps) {
+		return o.splitFrom(ctx, span, at, data, emit, err, rejected == nil)
+	}
+
+	if err != nil {
+		if rejected == nil {
+			o.drop(ctx, data, err)
+		}
+
+		return err
+	}
+
+	emit(data)
+	return nil
+}
+
+// steps runs the steps starting at from until the end or the next splitter. It returns the index
+// of that splitter, or the number of steps when there is nothing left to run.
+// In run-all mode a rejecting step does not stop the later ones, the splitters included, the first error,
+// rejected when set, is returned and the value stays as it was before the rejecting step.
+func (o *options[T]) steps(ctx context.Context, span Span, from int, data T, rejected error) (T, int, error) {
+	firstErr := rejected
+	for idx := from; idx < len(o.pipeline.steps); idx++ {
+		if err := ctx.Err(); err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+
+			break
+		}
+
+		if o.pipeline.steps[idx].split != nil {
+			return data, idx, firstErr
+		}
+
+		result, err := o.call(ctx, idx, data)
+		if span != nil {
+			span.Step(o.pipeline.steps[idx].name, err != nil)
+		}
+
+		if err != nil {
+			if firstErr == nil {
+				firstErr = stepError(o.pipeline.steps[idx].name, err)
+			}
+
+			if !o.runAll {
+				break
+			}
+
+			continue
+		}
+
+		data = result
+	}
+
+	return data, len(o.pipeline.steps), firstErr
+}
+
+// splitFrom expands data with the splitter at idx and runs the following steps on each part.
+// A value already rejected in run-all mode is only reported, once and when report is set,
+// after its parts went through the following steps.
+func (o *options[T]) splitFrom(ctx context.Context, span Span, idx int, data T, emit func(T), rejected error, report bool) error {
+	parts := o.split(ctx, idx, data)
+	if span != nil {
+		span.Step(o.pipeline.steps[idx].name, len(parts) == 0)
+	}
+
+	if rejected != nil {
+		for i := range parts {
+			_ = o.runFrom(ctx, span, idx+1, parts[i], emit, rejected)
+		}
+
+		if report {
+			o.drop(ctx, data, rejected)
+		}
+
+		return rejected
+	}
+
+	if len(parts) == 0 {
+		err := &StepError{Step: o.pipeline.steps[idx].name, Err: ErrDropped}
+		o.drop(ctx, data, err)
+		return err
+	}
+
+	// parts were each reported by runFrom already, only the first error is kept
+	var firstErr error
+	emitted := false
+	for i := range parts {
+		if err := o.runFrom(ctx, span, idx+1, parts[i], emit, nil); err == nil {
+			emitted = true
+		} else if firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	if emitted {
+		return nil
+	}
+
+	return firstErr
+}
+
+// drop reports a rejected value to the error handler and the dead letter channel
+func (o *options[T]) drop(ctx context.Context, data T, err error) {
+	if o.errorHandler != nil {
+		o.errorHandler(data, err)
+	}
+
+	if o.deadLetter != nil {
+		offer(o.deadLetter, data, o.deadLetterPolicy, ctx.Done())
+	}
+}
+
+func (o *options[T]) call(ctx context.Context, idx int, data T) (result T, err error) {
+	if o.stats != nil || o.logger != nil {
+		start := o.clock.Now()
+		defer func() {
+			o.observe(ctx, idx, err, o.clock.Now().Sub(start))
+		}()
+	}
+
+	if o.timeout <= 0 {
+		return invoke(ctx, o.pipeline.steps[idx].fn, o.panicHandler, data)
+	}
+
+	return o.invokeTimeout(ctx, idx, data)
+}
+
+func (o *options[T]) split(ctx context.Context, idx int, data T) (parts []T) {
+	if o.stats != nil || o.logger != nil {
+		start := o.clock.Now()
+		defer func() {
+			var err error
+			if len(parts) == 0 {
+				err = ErrDropped
+			}
+
+			o.observe(ctx, idx, err, o.clock.Now().Sub(start))
+		}()
+	}
+
+	if o.panicHandler != nil {
+		defer func() {
+			if r := recover(); r != nil {
+				o.panicHandler(data, r)
+				parts = nil
+			}
+		}()
+	}
+
+	return o.pipeline.steps[idx].split(data)
+}
+
+// invoke does not take the options so that a Pipeline.Run runner can stay on the stack
+func invoke[T any](ctx context.Context, fn func(context.Context, T) (T, error), panicHandler func(T, any), data T) (result T, err error) {
+	if panicHandler != nil {
+		defer func() {
+			if r := recover(); r != nil {
+				panicHandler(data, r)
+				result, err = data, ErrPanic
+			}
+		}()
+	}
+
+	return fn(ctx, data)
 }
@@ -0,0 +1,69 @@
+// Package otel traces channel pipelines with OpenTelemetry.
+//
+//	fanout := channel.NewFanout[Order](
+//		channel.WithName[Order]("orders"),
+//		channel.WithTracer[Order](otel.NewTracer(otelapi.Tracer("orders"))),
+//	)
+//	fanout.SendContext(r.Context(), order)
+package otel
+
+import (
+	"context"
+	"errors"
+	"github.com/lamlv2305/rok/channel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	defaultSpanName = "channel"
+	stepEvent       = "middleware"
+)
+
+type tracer struct {
+	tracer trace.Tracer
+}
+
+// NewTracer adapts t to channel.Tracer: one span per value, named after the pipeline,
+// and one event per middleware with its name and decision. A dropped value only sets attributes,
+// the span gets the error status when a middleware panicked or timed out.
+func NewTracer(t trace.Tracer) channel.Tracer {
+	return tracer{tracer: t}
+}
+
+func (t tracer) Start(ctx context.Context, name string) (context.Context, channel.Span) {
+	if name == "" {
+		name = defaultSpanName
+	}
+
+	ctx, span := t.tracer.Start(ctx, name, trace.WithAttributes(attribute.String("channel.name", name)))
+	return ctx, spanAdapter{span: span}
+}
+
+type spanAdapter struct {
+	span trace.Span
+}
+
+func (s spanAdapter) Step(name string, dropped bool) {
+	s.span.AddEvent(stepEvent, trace.WithAttributes(
+		attribute.String("middleware.name", name),
+		attribute.Bool("middleware.dropped", dropped),
+	))
+}
+
+func (s spanAdapter) End(err error) {
+	s.span.SetAttributes(attribute.Bool("channel.dropped", err != nil))
+	switch {
+	case err == nil:
+	case errors.Is(err, channel.ErrPanic), errors.Is(err, channel.ErrTimeout):
+		// the middleware failed instead of deciding, that is what the error status is for
+		s.span.RecordError(err)
+		s.span.SetStatus(codes.Error, err.Error())
+	default:
+		// a filter doing its job, not an error
+		s.span.SetAttributes(attribute.String("channel.drop_reason", err.Error()))
+	}
+
+	s.span.End()
+}
@@ -0,0 +1,105 @@
+package otel_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/lamlv2305/rok/channel"
+	"github.com/lamlv2305/rok/channel/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestTracer(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	defer provider.Shutdown(context.Background())
+
+	c, err := channel.New(8,
+		channel.WithName[int]("orders"),
+		channel.WithTracer[int](otel.NewTracer(provider.Tracer("test"))),
+		channel.WithPanicHandler(func(int, any) {}),
+		channel.WithStage(
+			channel.Named("positive", channel.Middleware[int](func(v int) bool { return v > 0 })),
+			channel.Named("small", channel.Middleware[int](func(v int) bool {
+				if v > 100 {
+					panic("too big")
+				}
+
+				return true
+			})),
+		),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		value   int
+		events  int
+		dropped bool
+		status  codes.Code
+	}{
+		{name: "delivered", value: 1, events: 2, status: codes.Unset},
+		{name: "dropped", value: -1, events: 1, dropped: true, status: codes.Unset},
+		{name: "panicked", value: 101, events: 2, dropped: true, status: codes.Error},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exporter.Reset()
+			_ = c.Send(tt.value)
+
+			spans := exporter.GetSpans()
+			if len(spans) != 1 {
+				t.Fatalf("got %d spans, want 1", len(spans))
+			}
+
+			span := spans[0]
+			if span.Name != "orders" {
+				t.Fatalf("span named %q, want orders", span.Name)
+			}
+
+			var steps []sdktrace.Event
+			for _, event := range span.Events {
+				if event.Name == "middleware" {
+					steps = append(steps, event)
+				}
+			}
+
+			if len(steps) != tt.events {
+				t.Fatalf("got %d middleware events, want %d", len(steps), tt.events)
+			}
+
+			if got := attr(span.Attributes, "channel.dropped"); got != attribute.BoolValue(tt.dropped) {
+				t.Fatalf("channel.dropped is %v, want %v", got.Emit(), tt.dropped)
+			}
+
+			if span.Status.Code != tt.status {
+				t.Fatalf("status %v, want %v", span.Status.Code, tt.status)
+			}
+
+			if reason := attr(span.Attributes, "channel.drop_reason").AsString(); (reason != "") != (tt.dropped && tt.status == codes.Unset) {
+				t.Fatalf("channel.drop_reason is %q for a %s value", reason, tt.name)
+			}
+
+			last := steps[len(steps)-1]
+			if got := attr(last.Attributes, "middleware.dropped"); got != attribute.BoolValue(tt.dropped) {
+				t.Fatalf("last middleware.dropped is %v, want %v", got.Emit(), tt.dropped)
+			}
+		})
+	}
+}
+
+func attr(attrs []attribute.KeyValue, key attribute.Key) attribute.Value {
+	for _, kv := range attrs {
+		if kv.Key == key {
+			return kv.Value
+		}
+	}
+
+	return attribute.Value{}
+}
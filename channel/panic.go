@@ -0,0 +1,10 @@
+package channel
+
+// WithPanicHandler recovers a panicking middleware: the value is dropped with ErrPanic,
+// fn receives the value and the recovered panic, and the next value is processed as usual.
+// Without a handler a panic is not recovered by the pipeline.
+func WithPanicHandler[T any](fn func(T, any)) WithOptions[T] {
+	return func(o *options[T]) {
+		o.panicHandler = fn
+	}
+}
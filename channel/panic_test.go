@@ -0,0 +1,79 @@
+package channel_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/lamlv2305/rok/channel"
+)
+
+func TestPanicHandler(t *testing.T) {
+	var ran []string
+	var recovered []any
+	var panicked []int
+
+	c, err := channel.New(4,
+		channel.WithPanicHandler(func(v int, r any) {
+			panicked = append(panicked, v)
+			recovered = append(recovered, r)
+		}),
+		channel.WithMiddleware(
+			func(int) bool {
+				ran = append(ran, "first")
+				return true
+			},
+			func(v int) bool {
+				ran = append(ran, "second")
+				if v == 1 {
+					panic("boom")
+				}
+
+				return true
+			},
+			func(int) bool {
+				ran = append(ran, "third")
+				return true
+			},
+		),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := c.Send(1); !errors.Is(err, channel.ErrPanic) {
+		t.Fatalf("Send returned %v, want ErrPanic", err)
+	}
+
+	if len(ran) != 2 || ran[1] != "second" {
+		t.Fatalf("ran %v, want the third middleware skipped", ran)
+	}
+
+	if len(panicked) != 1 || panicked[0] != 1 || recovered[0] != "boom" {
+		t.Fatalf("handler got %v and %v, want the value 1 and boom", panicked, recovered)
+	}
+
+	// the next value goes through every middleware as usual
+	ran = nil
+	if err := c.Send(2); err != nil {
+		t.Fatalf("Send after a panic returned %v", err)
+	}
+
+	if len(ran) != 3 || c.Len() != 1 {
+		t.Fatalf("ran %v with %d values buffered, want all three and 1", ran, c.Len())
+	}
+}
+
+func TestPanicWithoutHandler(t *testing.T) {
+	c, err := channel.New(1, channel.WithMiddleware(func(int) bool { panic("boom") }))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer func() {
+		if r := recover(); r != "boom" {
+			t.Fatalf("recovered %v, want the panic to reach the caller", r)
+		}
+	}()
+
+	_ = c.Send(1)
+}
@@ -0,0 +1,81 @@
+package channel
+
+import (
+	"context"
+	"slices"
+)
+
+// step is the common shape every kind of middleware is adapted into,
+// so all of them run in registration order
+type step[T any] struct {
+	name string
+	fn   func(context.Context, T) (T, error)
+	// set instead of fn for splitters
+	split func(T) []T
+	// lower runs first, see WithPriorityMiddleware
+	priority int
+	// set by stages keeping time, called with the clock of WithClock
+	clock func(Clock)
+}
+
+// Pipeline is an ordered list of middlewares, it can be built once
+// and shared between channels via WithPipeline
+type Pipeline[T any] struct {
+	steps     []step[T]
+	splitters int
+}
+
+func NewPipeline[T any]() *Pipeline[T] {
+	return &Pipeline[T]{}
+}
+
+func (p *Pipeline[T]) Use(middlewares ...Middleware[T]) *Pipeline[T] {
+	for idx := range middlewares {
+		p.add(middlewares[idx].step())
+	}
+
+	return p
+}
+
+// UseStage appends stages of any kind, see Stage
+func (p *Pipeline[T]) UseStage(stages ...Stage[T]) *Pipeline[T] {
+	for idx := range stages {
+		p.add(stages[idx].step())
+	}
+
+	return p
+}
+
+// Run reports whether data passes every middleware, stopping at the first one that drops it
+func (p *Pipeline[T]) Run(data T) bool {
+	o := options[T]{pipeline: *p, clock: systemClock{}}
+	return o.run(context.Background(), data, discard[T]) == nil
+}
+
+func discard[T any](T) {}
+
+func (p *Pipeline[T]) Len() int {
+	return len(p.steps)
+}
+
+// add keeps steps sorted by priority, a new step goes after the ones with the same priority
+func (p *Pipeline[T]) add(steps ...step[T]) {
+	for _, s := range steps {
+		at := len(p.steps)
+		for at > 0 && p.steps[at-1].priority > s.priority {
+			at--
+		}
+
+		p.steps = slices.Insert(p.steps, at, s)
+		if s.split != nil {
+			p.splitters++
+		}
+	}
+}
+
+// WithPipeline appends every middleware of p, p can be reused afterward
+func WithPipeline[T any](p *Pipeline[T]) WithOptions[T] {
+	return func(o *options[T]) {
+		o.pipeline.add(p.steps...)
+	}
+}
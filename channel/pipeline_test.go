@@ -0,0 +1,65 @@
+package channel_test
+
+import (
+	"testing"
+
+	"github.com/lamlv2305/rok/channel"
+)
+
+func TestPipelineRunAllocs(t *testing.T) {
+	p := channel.NewPipeline[int]().
+		Use(func(v int) bool { return v >= 0 }).
+		UseStage(
+			channel.Transform[int](func(v int) (int, bool) { return v + 1, true }),
+			channel.ErrMiddleware[int](func(int) error { return nil }),
+		)
+
+	if allocs := testing.AllocsPerRun(1000, func() {
+		if !p.Run(1) {
+			t.Fatal("value dropped")
+		}
+	}); allocs != 0 {
+		t.Fatalf("Run allocates %v times per call, want 0", allocs)
+	}
+}
+
+func benchmarkRun(b *testing.B, middlewares int) {
+	steps := make([]channel.Middleware[int], middlewares)
+	for idx := range steps {
+		steps[idx] = func(v int) bool { return v >= 0 }
+	}
+
+	b.Run("pipeline", func(b *testing.B) {
+		p := channel.NewPipeline[int]().Use(steps...)
+		b.ReportAllocs()
+		for range b.N {
+			p.Run(1)
+		}
+	})
+
+	for _, stats := range []bool{false, true} {
+		params := []channel.WithOptions[int]{channel.WithMiddleware(steps...)}
+		name := "channel"
+		if stats {
+			params = append(params, channel.WithStats[int]())
+			name += "/stats"
+		}
+
+		b.Run(name, func(b *testing.B) {
+			c, err := channel.New(1, params...)
+			if err != nil {
+				b.Fatal(err)
+			}
+
+			b.ReportAllocs()
+			for range b.N {
+				_ = c.Send(1)
+				c.TryRecv()
+			}
+		})
+	}
+}
+
+func BenchmarkRun_None(b *testing.B) { benchmarkRun(b, 0) }
+func BenchmarkRun_One(b *testing.B)  { benchmarkRun(b, 1) }
+func BenchmarkRun_Many(b *testing.B) { benchmarkRun(b, 8) }
@@ -0,0 +1,51 @@
+package channel
+
+// OverflowPolicy decides what happens to a value when its destination buffer is full
+type OverflowPolicy int
+
+const (
+	// Block waits until there is room
+	Block OverflowPolicy = iota
+	// DropNewest discards the value being sent
+	DropNewest
+	// DropOldest discards the oldest buffered value to make room
+	DropOldest
+)
+
+// offer sends data to ch following policy and reports whether data was buffered.
+// DropOldest is only safe when the caller is the single writer of ch, on an unbuffered ch
+// it is DropNewest since there is never anything to evict.
+func offer[T any](ch chan T, data T, policy OverflowPolicy, done <-chan struct{}) bool {
+	switch policy {
+	case DropNewest:
+		select {
+		case ch <- data:
+			return true
+		default:
+			return false
+		}
+	case DropOldest:
+		for {
+			select {
+			case ch <- data:
+				return true
+			default:
+			}
+
+			// nothing to evict, e.g. an unbuffered ch without a reader: behave like DropNewest
+			// rather than spinning until a reader shows up
+			select {
+			case <-ch:
+			default:
+				return false
+			}
+		}
+	default:
+		select {
+		case ch <- data:
+			return true
+		case <-done:
+			return false
+		}
+	}
+}
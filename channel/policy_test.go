@@ -0,0 +1,117 @@
+package channel
+
+import (
+	"testing"
+	"time"
+)
+
+// within fails the test when fn does not return before the deadline
+func within(t *testing.T, d time.Duration, fn func()) {
+	t.Helper()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		fn()
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(d):
+		t.Fatalf("did not return within %s", d)
+	}
+}
+
+func TestOffer(t *testing.T) {
+	tests := []struct {
+		name     string
+		buffer   int
+		policy   OverflowPolicy
+		want     bool
+		buffered []int
+	}{
+		{name: "drop newest keeps the buffer", buffer: 2, policy: DropNewest, want: false, buffered: []int{1, 2}},
+		{name: "drop oldest evicts the head", buffer: 2, policy: DropOldest, want: true, buffered: []int{2, 3}},
+		{name: "drop oldest on unbuffered drops", buffer: 0, policy: DropOldest, want: false},
+		{name: "drop newest on unbuffered drops", buffer: 0, policy: DropNewest, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ch := make(chan int, tt.buffer)
+			for i := 1; i <= tt.buffer; i++ {
+				ch <- i
+			}
+
+			var got bool
+			within(t, time.Second, func() {
+				got = offer(ch, 3, tt.policy, nil)
+			})
+
+			if got != tt.want {
+				t.Fatalf("offer = %v, want %v", got, tt.want)
+			}
+
+			close(ch)
+			var buffered []int
+			for v := range ch {
+				buffered = append(buffered, v)
+			}
+
+			if len(buffered) != len(tt.buffered) {
+				t.Fatalf("buffered %v, want %v", buffered, tt.buffered)
+			}
+
+			for idx := range buffered {
+				if buffered[idx] != tt.buffered[idx] {
+					t.Fatalf("buffered %v, want %v", buffered, tt.buffered)
+				}
+			}
+		})
+	}
+}
+
+func TestOfferBlockGivesUpOnDone(t *testing.T) {
+	done := make(chan struct{})
+	close(done)
+
+	within(t, time.Second, func() {
+		if offer(make(chan int), 1, Block, done) {
+			t.Error("offer reported a send nobody received")
+		}
+	})
+}
+
+func TestDropOldestUnbufferedDoesNotSpin(t *testing.T) {
+	t.Run("fanout", func(t *testing.T) {
+		f := NewFanout[int](WithOverflow[int](DropOldest))
+		sub := f.Subscribe(0, nil)
+		defer sub.Close()
+
+		within(t, time.Second, func() {
+			f.Send(1)
+		})
+	})
+
+	t.Run("fan out target", func(t *testing.T) {
+		source := make(chan int)
+		target := make(chan int)
+		stop := FanOut(source, Target[int]{Ch: target, Policy: DropOldest})
+
+		within(t, time.Second, func() {
+			source <- 1
+			source <- 2
+			stop()
+		})
+	})
+
+	t.Run("bus", func(t *testing.T) {
+		bus := NewBus[int](0)
+		ch := bus.Subscribe("orders", WithOverflow[int](DropOldest))
+		defer bus.Unsubscribe(ch)
+
+		within(t, time.Second, func() {
+			bus.Publish("orders", 1)
+		})
+	})
+}
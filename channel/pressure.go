@@ -0,0 +1,63 @@
+package channel
+
+// WithHighWaterMark calls fn once each time the buffer fill of a Channel reaches ratio,
+// it fires again only after the fill went back under the low water mark.
+func WithHighWaterMark[T any](ratio float64, fn func()) WithOptions[T] {
+	return func(o *options[T]) {
+		o.highWater, o.onHighWater = ratio, fn
+	}
+}
+
+// WithLowWaterMark calls fn once the buffer fill of a Channel, after reaching the high water mark,
+// drops back to ratio. Without it the low water mark is just under the high one.
+func WithLowWaterMark[T any](ratio float64, fn func()) WithOptions[T] {
+	return func(o *options[T]) {
+		o.lowWater, o.onLowWater = ratio, fn
+	}
+}
+
+// Pressure is the buffer fill ratio, from 0 (empty) to 1 (full)
+func (c *Channel[T]) Pressure() float64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.pressure()
+}
+
+func (c *Channel[T]) pressure() float64 {
+	return float64(c.queue.len()) / float64(c.size)
+}
+
+// watermark must be called with mu held, the returned callback must be called after unlocking
+func (c *Channel[T]) watermark() func() {
+	if c.options.onHighWater == nil && c.options.onLowWater == nil {
+		return noop
+	}
+
+	pressure := c.pressure()
+	if !c.high && c.options.highWater > 0 && pressure >= c.options.highWater {
+		c.high = true
+		return orNoop(c.options.onHighWater)
+	}
+
+	relieved := pressure < c.options.highWater
+	if c.options.onLowWater != nil || c.options.lowWater > 0 {
+		relieved = pressure <= c.options.lowWater
+	}
+
+	if c.high && relieved {
+		c.high = false
+		return orNoop(c.options.onLowWater)
+	}
+
+	return noop
+}
+
+func noop() {}
+
+func orNoop(fn func()) func() {
+	if fn == nil {
+		return noop
+	}
+
+	return fn
+}
@@ -0,0 +1,67 @@
+package channel_test
+
+import (
+	"testing"
+
+	"github.com/lamlv2305/rok/channel"
+)
+
+func TestWaterMarks(t *testing.T) {
+	var events []string
+	c, err := channel.New(10,
+		channel.WithHighWaterMark[int](0.8, func() { events = append(events, "high") }),
+		channel.WithLowWaterMark[int](0.2, func() { events = append(events, "low") }),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := func(pressure float64, wantEvents ...string) {
+		t.Helper()
+
+		if got := c.Pressure(); got != pressure {
+			t.Fatalf("Pressure %v, want %v", got, pressure)
+		}
+
+		if len(events) != len(wantEvents) {
+			t.Fatalf("events %v, want %v", events, wantEvents)
+		}
+
+		for idx := range events {
+			if events[idx] != wantEvents[idx] {
+				t.Fatalf("events %v, want %v", events, wantEvents)
+			}
+		}
+	}
+
+	for idx := range 7 {
+		_ = c.Send(idx)
+	}
+	want(0.7)
+
+	_ = c.Send(7)
+	want(0.8, "high")
+
+	// staying above the mark fires nothing more
+	_ = c.Send(8)
+	_, _ = c.Recv()
+	_, _ = c.Recv()
+	want(0.7, "high")
+
+	for range 4 {
+		_, _ = c.Recv()
+	}
+	want(0.3, "high")
+
+	_, _ = c.Recv()
+	want(0.2, "high", "low")
+
+	_, _ = c.Recv()
+	want(0.1, "high", "low")
+
+	// a new crossing fires again
+	for idx := range 7 {
+		_ = c.Send(idx)
+	}
+	want(0.8, "high", "low", "high")
+}
@@ -0,0 +1,90 @@
+package channel
+
+import "container/heap"
+
+// WithPriority makes Recv return the buffered value with the highest priority first,
+// values of equal priority keep their send order.
+// The buffer becomes a heap so Send and Recv cost O(log n), and DropOldest discards
+// the lowest priority value, which costs O(n).
+func WithPriority[T any](priority func(T) int) WithOptions[T] {
+	return func(o *options[T]) {
+		o.priority = priority
+	}
+}
+
+type prioritized[T any] struct {
+	data     T
+	priority int
+	seq      uint64
+}
+
+type priorityQueue[T any] struct {
+	items    prioritizedHeap[T]
+	priority func(T) int
+	seq      uint64
+}
+
+func newPriorityQueue[T any](capacity int, priority func(T) int) *priorityQueue[T] {
+	return &priorityQueue[T]{
+		items:    make(prioritizedHeap[T], 0, capacity),
+		priority: priority,
+	}
+}
+
+func (q *priorityQueue[T]) push(data T) {
+	heap.Push(&q.items, prioritized[T]{data: data, priority: q.priority(data), seq: q.seq})
+	q.seq++
+}
+
+func (q *priorityQueue[T]) pop() T {
+	return heap.Pop(&q.items).(prioritized[T]).data
+}
+
+func (q *priorityQueue[T]) evict() {
+	lowest := 0
+	for idx := range q.items {
+		if q.items.Less(lowest, idx) {
+			lowest = idx
+		}
+	}
+
+	heap.Remove(&q.items, lowest)
+}
+
+func (q *priorityQueue[T]) len() int {
+	return len(q.items)
+}
+
+func (q *priorityQueue[T]) clear() {
+	q.items = q.items[:0]
+}
+
+// prioritizedHeap implements heap.Interface, highest priority then lowest seq on top
+type prioritizedHeap[T any] []prioritized[T]
+
+func (h prioritizedHeap[T]) Len() int {
+	return len(h)
+}
+
+func (h prioritizedHeap[T]) Less(i, j int) bool {
+	if h[i].priority != h[j].priority {
+		return h[i].priority > h[j].priority
+	}
+
+	return h[i].seq < h[j].seq
+}
+
+func (h prioritizedHeap[T]) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+}
+
+func (h *prioritizedHeap[T]) Push(x any) {
+	*h = append(*h, x.(prioritized[T]))
+}
+
+func (h *prioritizedHeap[T]) Pop() any {
+	old := *h
+	item := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return item
+}
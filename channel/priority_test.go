@@ -0,0 +1,52 @@
+package channel_test
+
+import (
+	"testing"
+
+	"github.com/lamlv2305/rok/channel"
+)
+
+type job struct {
+	priority int
+	id       string
+}
+
+func TestPriority(t *testing.T) {
+	tests := []struct {
+		name string
+		sent []job
+		want []string
+	}{
+		{
+			name: "highest first",
+			sent: []job{{1, "low"}, {3, "high"}, {2, "mid"}},
+			want: []string{"high", "mid", "low"},
+		},
+		{
+			name: "equal priorities keep their order",
+			sent: []job{{1, "a"}, {2, "b"}, {1, "c"}, {2, "d"}, {1, "e"}},
+			want: []string{"b", "d", "a", "c", "e"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := channel.New(len(tt.sent), channel.WithPriority(func(j job) int { return j.priority }))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for _, j := range tt.sent {
+				if err := c.Send(j); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			for _, want := range tt.want {
+				if got, ok := c.Recv(); !ok || got.id != want {
+					t.Fatalf("Recv got %v, want %s", got, want)
+				}
+			}
+		})
+	}
+}
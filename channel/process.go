@@ -0,0 +1,52 @@
+package channel
+
+import "context"
+
+// Process runs the middlewares configured by params against a single value, without any channel,
+// and returns the value as the last middleware left it along with whether it survived.
+// With a splitter, the first surviving value is returned.
+// Error handler, dead letter, metrics and tracer apply as they do on the channel path,
+// side effects do not. It is safe for concurrent use as long as the middlewares are.
+// Every call applies params again, on a hot path build a Processor once instead.
+func Process[T any](data T, params ...WithOptions[T]) (T, bool) {
+	return ProcessContext(context.Background(), data, params...)
+}
+
+func ProcessContext[T any](ctx context.Context, data T, params ...WithOptions[T]) (T, bool) {
+	return NewProcessor(params...).ProcessContext(ctx, data)
+}
+
+// Processor is Process with params applied once, to run many values through the same middlewares.
+// Without a splitter it does not allocate beyond what the middlewares and options do.
+type Processor[T any] struct {
+	options options[T]
+}
+
+func NewProcessor[T any](params ...WithOptions[T]) *Processor[T] {
+	return &Processor[T]{options: applyOptions(params)}
+}
+
+func (p *Processor[T]) Process(data T) (T, bool) {
+	return p.ProcessContext(context.Background(), data)
+}
+
+func (p *Processor[T]) ProcessContext(ctx context.Context, data T) (T, bool) {
+	if p.options.pipeline.splitters == 0 {
+		result, err := p.options.runValue(ctx, data)
+		return result, err == nil
+	}
+
+	var result T
+	emitted := false
+	err := p.options.run(ctx, data, func(value T) {
+		if !emitted {
+			result, emitted = value, true
+		}
+	})
+
+	if err != nil {
+		return data, false
+	}
+
+	return result, true
+}
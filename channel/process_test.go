@@ -0,0 +1,66 @@
+package channel_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/lamlv2305/rok/channel"
+)
+
+func TestProcessMatchesChannel(t *testing.T) {
+	params := func() []channel.WithOptions[int] {
+		return []channel.WithOptions[int]{
+			channel.WithMiddleware(func(v int) bool { return v >= 0 }),
+			channel.WithTransform(func(v int) (int, bool) { return v * 3, v != 7 }),
+			channel.WithErrMiddleware(func(v int) error {
+				if v%2 == 1 {
+					return errors.New("odd")
+				}
+
+				return nil
+			}),
+		}
+	}
+
+	c, err := channel.New(1, params()...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	processor := channel.NewProcessor(params()...)
+	for v := -5; v < 20; v++ {
+		got, ok := channel.Process(v, params()...)
+		if prebuilt, prebuiltOK := processor.Process(v); prebuilt != got || prebuiltOK != ok {
+			t.Fatalf("Processor(%d) returned %d, %v, Process returned %d, %v", v, prebuilt, prebuiltOK, got, ok)
+		}
+
+		sendErr := c.Send(v)
+		if ok != (sendErr == nil) {
+			t.Fatalf("Process(%d) survived %v, Send returned %v", v, ok, sendErr)
+		}
+
+		if !ok {
+			continue
+		}
+
+		if want, _ := c.Recv(); got != want {
+			t.Fatalf("Process(%d) returned %d, the channel delivered %d", v, got, want)
+		}
+	}
+}
+
+func TestProcessorAllocs(t *testing.T) {
+	p := channel.NewProcessor(
+		channel.WithMiddleware(func(v int) bool { return v >= 0 }),
+		channel.WithTransform(func(v int) (int, bool) { return v + 1, true }),
+		channel.WithErrMiddleware(func(int) error { return nil }),
+	)
+
+	if allocs := testing.AllocsPerRun(1000, func() {
+		if _, ok := p.Process(1); !ok {
+			t.Fatal("value dropped")
+		}
+	}); allocs != 0 {
+		t.Fatalf("Process allocates %v times per call, want 0", allocs)
+	}
+}
@@ -0,0 +1,62 @@
+package channel
+
+// queue is the buffer behind a Channel, capacity is enforced by the Channel
+type queue[T any] interface {
+	push(T)
+	pop() T
+	// evict removes the value to give up first when the buffer overflows
+	evict()
+	len() int
+	clear()
+}
+
+// fifo is a growable ring buffer
+type fifo[T any] struct {
+	ring  []T
+	head  int
+	count int
+}
+
+func newFifo[T any](capacity int) *fifo[T] {
+	return &fifo[T]{ring: make([]T, capacity)}
+}
+
+func (q *fifo[T]) push(data T) {
+	if q.count == len(q.ring) {
+		ring := make([]T, max(2*len(q.ring), 1))
+		for idx := 0; idx < q.count; idx++ {
+			ring[idx] = q.ring[(q.head+idx)%len(q.ring)]
+		}
+
+		q.ring, q.head = ring, 0
+	}
+
+	q.ring[(q.head+q.count)%len(q.ring)] = data
+	q.count++
+}
+
+func (q *fifo[T]) pop() T {
+	var zero T
+	data := q.ring[q.head]
+	q.ring[q.head] = zero
+	q.head = (q.head + 1) % len(q.ring)
+	q.count--
+	return data
+}
+
+func (q *fifo[T]) evict() {
+	q.pop()
+}
+
+func (q *fifo[T]) len() int {
+	return q.count
+}
+
+func (q *fifo[T]) clear() {
+	var zero T
+	for idx := range q.ring {
+		q.ring[idx] = zero
+	}
+
+	q.head, q.count = 0, 0
+}
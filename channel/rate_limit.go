@@ -0,0 +1,108 @@
+package channel
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// RateLimit lets through at most perSecond values on average with bursts of up to burst values,
+// values arriving when no token is left are dropped.
+// The returned stage is safe for concurrent use, its state is shared by every pipeline it is registered in.
+func RateLimit[T any](perSecond float64, burst int) Stage[T] {
+	bucket := newTokenBucket(perSecond, burst)
+	return stageFunc[T](func() step[T] {
+		return step[T]{
+			fn: func(_ context.Context, data T) (T, error) {
+				if !bucket.allow() {
+					return data, ErrDropped
+				}
+
+				return data, nil
+			},
+			clock: bucket.clock.set,
+		}
+	})
+}
+
+// RateLimitWait is like RateLimit but waits until a token is available instead of dropping,
+// the value is dropped with ctx.Err() if ctx is done first
+func RateLimitWait[T any](perSecond float64, burst int) Stage[T] {
+	bucket := newTokenBucket(perSecond, burst)
+	return stageFunc[T](func() step[T] {
+		return step[T]{
+			fn: func(ctx context.Context, data T) (T, error) {
+				return data, bucket.wait(ctx)
+			},
+			clock: bucket.clock.set,
+		}
+	})
+}
+
+type tokenBucket struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+	clock  *clockRef
+}
+
+func newTokenBucket(perSecond float64, burst int) *tokenBucket {
+	if burst < 1 {
+		burst = 1
+	}
+
+	return &tokenBucket{
+		rate:   perSecond,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		clock:  newClockRef(),
+	}
+}
+
+func (b *tokenBucket) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.refill()
+	if b.tokens < 1 {
+		return false
+	}
+
+	b.tokens--
+	return true
+}
+
+func (b *tokenBucket) wait(ctx context.Context) error {
+	for {
+		b.mu.Lock()
+		b.refill()
+		if b.tokens >= 1 {
+			b.tokens--
+			b.mu.Unlock()
+			return nil
+		}
+
+		// time until the next token, at least 1ms so a tiny rate does not spin
+		delay := time.Millisecond
+		if b.rate > 0 {
+			delay = max(delay, time.Duration((1-b.tokens)/b.rate*float64(time.Second)))
+		}
+		b.mu.Unlock()
+
+		if err := sleep(ctx, b.clock.get(), delay); err != nil {
+			return err
+		}
+	}
+}
+
+// refill must be called with mu held
+func (b *tokenBucket) refill() {
+	now := b.clock.get().Now()
+	if !b.last.IsZero() && b.rate > 0 {
+		b.tokens = min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	}
+
+	b.last = now
+}
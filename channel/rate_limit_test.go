@@ -0,0 +1,135 @@
+package channel_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/lamlv2305/rok/channel"
+	"github.com/lamlv2305/rok/channel/fakeclock"
+)
+
+func TestRateLimit(t *testing.T) {
+	tests := []struct {
+		name      string
+		perSecond float64
+		burst     int
+		// time between two values
+		every time.Duration
+		want  int
+	}{
+		// all at once: only the burst passes
+		{name: "burst", perSecond: 100, burst: 10, want: 10},
+		// 1000 values over 9.99s at 50/s: the burst plus one token every 20ms
+		{name: "sustained", perSecond: 50, burst: 10, every: 10 * time.Millisecond, want: 10 + 499},
+		// arriving slower than the rate: everything passes
+		{name: "under the rate", perSecond: 200, burst: 1, every: 10 * time.Millisecond, want: 1000},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := fakeclock.New(time.Unix(0, 0))
+			c, err := channel.New(1000,
+				channel.WithClock[int](clock),
+				channel.WithStage(channel.RateLimit[int](tt.perSecond, tt.burst)),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			passed := 0
+			for idx := range 1000 {
+				if idx > 0 {
+					clock.Advance(tt.every)
+				}
+
+				if err := c.Send(idx); err == nil {
+					passed++
+				} else if !errors.Is(err, channel.ErrDropped) {
+					t.Fatalf("Send returned %v", err)
+				}
+			}
+
+			if passed != tt.want {
+				t.Fatalf("%d of 1000 values passed, want %d", passed, tt.want)
+			}
+		})
+	}
+}
+
+func TestRateLimitConcurrent(t *testing.T) {
+	clock := fakeclock.New(time.Unix(0, 0))
+	c, err := channel.New(1000,
+		channel.WithClock[int](clock),
+		channel.WithStage(channel.RateLimit[int](1, 25)),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var passed atomic.Int64
+	var wg sync.WaitGroup
+	for idx := range 1000 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if c.Send(idx) == nil {
+				passed.Add(1)
+			}
+		}()
+	}
+
+	wg.Wait()
+	if passed.Load() != 25 {
+		t.Fatalf("%d values passed, want the burst of 25", passed.Load())
+	}
+}
+
+func TestRateLimitWait(t *testing.T) {
+	clock := fakeclock.New(time.Unix(0, 0))
+	c, err := channel.New(4,
+		channel.WithClock[int](clock),
+		channel.WithStage(channel.RateLimitWait[int](1, 1)),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := c.Send(1); err != nil {
+		t.Fatal(err)
+	}
+
+	result := make(chan error, 1)
+	go func() {
+		result <- c.Send(2)
+	}()
+
+	// waiting on the injected clock for the next token
+	deadline := time.Now().Add(time.Second)
+	for clock.Pending() == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("RateLimitWait never waited on the clock")
+		}
+
+		time.Sleep(time.Millisecond)
+	}
+
+	clock.Advance(time.Second)
+	select {
+	case err := <-result:
+		if err != nil {
+			t.Fatalf("Send returned %v once the token is back", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Send still blocked after the token is back")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := c.SendContext(ctx, 3); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Send with a cancelled context returned %v, want context.Canceled", err)
+	}
+}
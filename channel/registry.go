@@ -0,0 +1,69 @@
+package channel
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Registry maps names to stages of any kind so that pipelines can be assembled from configuration, see Build
+type Registry[T any] struct {
+	mu     sync.RWMutex
+	stages map[string]Stage[T]
+}
+
+func NewRegistry[T any]() *Registry[T] {
+	return &Registry[T]{stages: map[string]Stage[T]{}}
+}
+
+// Register adds stage under name, a name can only be registered once
+func (r *Registry[T]) Register(name string, stage Stage[T]) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.stages[name]; ok {
+		return fmt.Errorf("channel: middleware %q already registered", name)
+	}
+
+	r.stages[name] = stage
+	return nil
+}
+
+// Names returns the registered names, sorted
+func (r *Registry[T]) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.names()
+}
+
+// names must be called with mu held
+func (r *Registry[T]) names() []string {
+	names := make([]string, 0, len(r.stages))
+	for name := range r.stages {
+		names = append(names, name)
+	}
+
+	slices.Sort(names)
+	return names
+}
+
+// Build returns the stages of spec in order, each wrapped with Named under its registry name,
+// ready for WithStage. A name may appear several times. An unknown name fails with
+// ErrUnknownMiddleware and the list of the available ones.
+func Build[T any](registry *Registry[T], spec []string) ([]Stage[T], error) {
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+
+	stages := make([]Stage[T], 0, len(spec))
+	for _, name := range spec {
+		stage, ok := registry.stages[name]
+		if !ok {
+			return nil, fmt.Errorf("%w %q, available: %s", ErrUnknownMiddleware, name, strings.Join(registry.names(), ", "))
+		}
+
+		stages = append(stages, Named(name, stage))
+	}
+
+	return stages, nil
+}
@@ -0,0 +1,67 @@
+package channel_test
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lamlv2305/rok/channel"
+)
+
+func TestRegistryBuild(t *testing.T) {
+	type request struct {
+		user string
+		id   string
+	}
+
+	registry := channel.NewRegistry[request]()
+	for name, stage := range map[string]channel.Stage[request]{
+		"auth":      channel.Middleware[request](func(r request) bool { return r.user != "" }),
+		"dedupe":    channel.Dedupe(func(r request) string { return r.id }, time.Minute),
+		"ratelimit": channel.RateLimit[request](1000, 10),
+	} {
+		if err := registry.Register(name, stage); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := registry.Register("auth", channel.Middleware[request](func(request) bool { return true })); err == nil {
+		t.Fatal("registered auth twice")
+	}
+
+	spec := []string{"auth", "dedupe", "ratelimit"}
+	stages, err := channel.Build(registry, spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p := channel.NewPipeline[request]().UseStage(stages...)
+	var names []string
+	for _, step := range p.Explain(request{user: "ann", id: "1"}) {
+		if step.Dropped {
+			t.Fatalf("step %s dropped a valid request", step.Name)
+		}
+		names = append(names, step.Name)
+	}
+
+	if !slices.Equal(names, spec) {
+		t.Fatalf("built steps %v, want %v", names, spec)
+	}
+
+	if !p.Run(request{user: "bob", id: "2"}) || p.Run(request{user: "bob", id: "2"}) || p.Run(request{id: "3"}) {
+		t.Fatal("built pipeline does not dedupe and authenticate")
+	}
+
+	_, err = channel.Build(registry, []string{"auth", "cache"})
+	if !errors.Is(err, channel.ErrUnknownMiddleware) {
+		t.Fatalf("unknown name returned %v, want ErrUnknownMiddleware", err)
+	}
+
+	for _, name := range append(spec, "cache") {
+		if !strings.Contains(err.Error(), name) {
+			t.Fatalf("error %q does not mention %s", err, name)
+		}
+	}
+}
@@ -8,27 +8,45 @@ func WithRelay[T any](relay int) func(*options[T]) {
 	}
 }
 
+// WithReplay keeps the last n values that passed the middlewares,
+// every new subscriber receives them before the live ones. Replayed values do not run
+// the middlewares again, Subscription.Replayed tells how many were replayed.
+func WithReplay[T any](n int) WithOptions[T] {
+	return WithRelay[T](n)
+}
+
+// RelaySlice is a ring buffer of the last size values
 type RelaySlice struct {
-	mu   *sync.RWMutex
-	data []any
-	size int
+	mu    *sync.RWMutex
+	data  []any
+	head  int
+	count int
+	size  int
 }
 
 func (r *RelaySlice) Add(data any) {
 	r.mu.Lock()
 
-	r.data = append(r.data, data)
-
-	// Remove first item to keep relay size
-	if len(r.data) > r.size {
-		r.data = r.data[len(r.data)-r.size:]
+	r.data[(r.head+r.count)%r.size] = data
+	if r.count < r.size {
+		r.count++
+	} else {
+		// overwrite the oldest item to keep relay size
+		r.head = (r.head + 1) % r.size
 	}
 
 	r.mu.Unlock()
 }
 
+// Get returns a copy of the values, oldest first
 func (r *RelaySlice) Get() []any {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
-	return r.data
+
+	result := make([]any, r.count)
+	for idx := range result {
+		result[idx] = r.data[(r.head+idx)%r.size]
+	}
+
+	return result
 }
@@ -0,0 +1,42 @@
+package channel_test
+
+import (
+	"testing"
+
+	"github.com/lamlv2305/rok/channel"
+)
+
+func TestReplay(t *testing.T) {
+	calls := 0
+	f := channel.NewFanout(
+		channel.WithReplay[int](3),
+		channel.WithMiddleware(func(v int) bool {
+			calls++
+			return true
+		}),
+	)
+
+	for idx := range 5 {
+		f.Send(idx)
+	}
+
+	sub := f.Subscribe(2, nil)
+	defer sub.Close()
+
+	if sub.Replayed() != 3 {
+		t.Fatalf("Replayed %d, want 3", sub.Replayed())
+	}
+
+	// replayed values already passed the middlewares
+	if calls != 5 {
+		t.Fatalf("middlewares ran %d times, want 5", calls)
+	}
+
+	f.Send(5)
+	f.Send(6)
+	for _, want := range []int{2, 3, 4, 5, 6} {
+		if got := <-sub.C(); got != want {
+			t.Fatalf("got %d, want %d", got, want)
+		}
+	}
+}
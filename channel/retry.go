@@ -0,0 +1,89 @@
+package channel
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// RetryOption configures Retry
+type RetryOption func(*retryOptions)
+
+type retryOptions struct {
+	jitter rand.Source
+}
+
+// WithJitter sets the random source used by Retry to spread the backoff, time seeded by default
+func WithJitter(source rand.Source) RetryOption {
+	return func(o *retryOptions) {
+		o.jitter = source
+	}
+}
+
+// Retry runs stage up to attempts times until it accepts the value and returns its last error otherwise,
+// each attempt starts from the value Retry received. The wait before the nth retry is backoff * 2^(n-1),
+// randomized between half and the full amount. It gives up early, with the last error, when ctx is done
+// or its deadline comes before the next try. Splitters are not supported.
+func Retry[T any](stage Stage[T], attempts int, backoff time.Duration, params ...RetryOption) Stage[T] {
+	var opts retryOptions
+	for _, param := range params {
+		param(&opts)
+	}
+
+	if opts.jitter == nil {
+		opts.jitter = rand.NewSource(time.Now().UnixNano())
+	}
+
+	attempts = max(attempts, 1)
+	clock := newClockRef()
+	var mu sync.Mutex
+	random := rand.New(opts.jitter)
+
+	return stageFunc[T](func() step[T] {
+		inner := stage.step()
+		if inner.split != nil {
+			panic("channel: Retry does not support splitters")
+		}
+
+		retry := func(ctx context.Context, data T) (T, error) {
+			delay := backoff
+			for attempt := 1; ; attempt++ {
+				result, err := inner.fn(ctx, data)
+				if err == nil {
+					return result, nil
+				}
+
+				if attempt == attempts {
+					return data, err
+				}
+
+				wait := delay
+				if half := int64(delay / 2); half > 0 {
+					mu.Lock()
+					wait = time.Duration(half + random.Int63n(half))
+					mu.Unlock()
+				}
+				delay *= 2
+
+				// context deadlines are wall clock time whatever the clock of the pipeline
+				if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < wait {
+					return data, err
+				}
+
+				if sleep(ctx, clock.get(), wait) != nil {
+					return data, err
+				}
+			}
+		}
+
+		return step[T]{
+			name: inner.name,
+			fn:   retry,
+			clock: func(c Clock) {
+				clock.set(c)
+				bindAll(inner)(c)
+			},
+		}
+	})
+}
@@ -0,0 +1,123 @@
+package channel_test
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/lamlv2305/rok/channel"
+	"github.com/lamlv2305/rok/channel/fakeclock"
+)
+
+var errTransient = errors.New("transient")
+
+func TestRetryAttempts(t *testing.T) {
+	tests := []struct {
+		name      string
+		attempts  int
+		failures  int
+		wantCalls int
+		wantErr   error
+	}{
+		{name: "gives up after attempts", attempts: 4, failures: 10, wantCalls: 4, wantErr: errTransient},
+		{name: "stops at the first success", attempts: 4, failures: 2, wantCalls: 3},
+		{name: "no retry below one attempt", attempts: 0, failures: 10, wantCalls: 1, wantErr: errTransient},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			flaky := channel.ErrMiddleware[int](func(int) error {
+				calls++
+				if calls <= tt.failures {
+					return errTransient
+				}
+
+				return nil
+			})
+
+			_, ok := channel.Process(1, channel.WithStage(channel.Retry[int](flaky, tt.attempts, time.Microsecond)))
+			if calls != tt.wantCalls {
+				t.Fatalf("called %d times, want %d", calls, tt.wantCalls)
+			}
+
+			if ok != (tt.wantErr == nil) {
+				t.Fatalf("survived %v, want the error %v", ok, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRetryBackoff(t *testing.T) {
+	clock := fakeclock.New(time.Unix(0, 0))
+	var calls []time.Time
+	failing := channel.ErrMiddleware[int](func(int) error {
+		calls = append(calls, clock.Now())
+		return errTransient
+	})
+
+	c, err := channel.New(1,
+		channel.WithClock[int](clock),
+		channel.WithStage(channel.Retry[int](failing, 3, time.Second, channel.WithJitter(rand.NewSource(1)))),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result := make(chan error, 1)
+	go func() {
+		result <- c.Send(1)
+	}()
+
+	for {
+		select {
+		case err := <-result:
+			if !errors.Is(err, errTransient) {
+				t.Fatalf("Send returned %v, want the last error", err)
+			}
+
+			if len(calls) != 3 {
+				t.Fatalf("called %d times, want 3", len(calls))
+			}
+
+			// each wait is between half and all of backoff * 2^(n-1)
+			for idx, bound := range []time.Duration{time.Second, 2 * time.Second} {
+				wait := calls[idx+1].Sub(calls[idx])
+				if wait < bound/2 || wait > bound {
+					t.Fatalf("retry %d waited %s, want between %s and %s", idx+1, wait, bound/2, bound)
+				}
+			}
+
+			return
+		default:
+			// timers fire at their exact deadline whatever the step
+			if clock.Pending() > 0 {
+				clock.Advance(100 * time.Millisecond)
+			}
+			time.Sleep(100 * time.Microsecond)
+		}
+	}
+}
+
+func TestRetryDeadline(t *testing.T) {
+	calls := 0
+	failing := channel.ErrMiddleware[int](func(int) error {
+		calls++
+		return errTransient
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	start := time.Now()
+	_, ok := channel.ProcessContext(ctx, 1, channel.WithStage(channel.Retry[int](failing, 5, time.Minute)))
+	if ok || calls != 1 {
+		t.Fatalf("survived %v after %d calls, want a single call", ok, calls)
+	}
+
+	if elapsed := time.Since(start); elapsed > 100*time.Millisecond {
+		t.Fatalf("took %s, want no wait past the deadline", elapsed)
+	}
+}
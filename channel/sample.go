@@ -0,0 +1,38 @@
+package channel
+
+import (
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// SampleEveryN lets through the nth, 2nth, 3nth... value and drops the others
+func SampleEveryN[T any](n int) Stage[T] {
+	if n <= 1 {
+		return Middleware[T](func(T) bool {
+			return true
+		})
+	}
+
+	var count atomic.Uint64
+	return Middleware[T](func(T) bool {
+		return count.Add(1)%uint64(n) == 0
+	})
+}
+
+// SampleFraction lets through each value with probability p.
+// source makes the sampling reproducible, nil uses a time seeded one.
+func SampleFraction[T any](p float64, source rand.Source) Stage[T] {
+	if source == nil {
+		source = rand.NewSource(time.Now().UnixNano())
+	}
+
+	var mu sync.Mutex
+	random := rand.New(source)
+	return Middleware[T](func(T) bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return random.Float64() < p
+	})
+}
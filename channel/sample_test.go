@@ -0,0 +1,53 @@
+package channel_test
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/lamlv2305/rok/channel"
+)
+
+func TestSampleEveryN(t *testing.T) {
+	for _, n := range []int{1, 2, 3, 7, 100, 10001} {
+		sample := channel.NewPipeline[int]().UseStage(channel.SampleEveryN[int](n))
+		passed := 0
+		for idx := range 10000 {
+			if sample.Run(idx) {
+				passed++
+			}
+		}
+
+		if want := 10000 / n; passed != want {
+			t.Fatalf("n=%d passed %d of 10000, want %d", n, passed, want)
+		}
+	}
+}
+
+func TestSampleFraction(t *testing.T) {
+	run := func(seed int64) []int {
+		sample := channel.NewPipeline[int]().UseStage(channel.SampleFraction[int](0.1, rand.NewSource(seed)))
+		var passed []int
+		for idx := range 10000 {
+			if sample.Run(idx) {
+				passed = append(passed, idx)
+			}
+		}
+
+		return passed
+	}
+
+	first, again := run(42), run(42)
+	if len(first) < 900 || len(first) > 1100 {
+		t.Fatalf("passed %d of 10000 with p=0.1", len(first))
+	}
+
+	if len(first) != len(again) {
+		t.Fatalf("the same source passed %d then %d values", len(first), len(again))
+	}
+
+	for idx := range first {
+		if first[idx] != again[idx] {
+			t.Fatal("the same source sampled different values")
+		}
+	}
+}
@@ -0,0 +1,18 @@
+package channel
+
+// WithShortCircuit selects how the pipeline reacts to a rejected value.
+//
+// true (default): the first middleware that drops the value stops the chain,
+// later middlewares are never called for that value.
+//
+// false: every middleware is called for every value, even after one of them
+// dropped it, and the value is dropped at the end if any of them rejected it.
+// A splitter still splits a rejected value, its parts go through the later middlewares
+// but none of them is delivered.
+// Use it when middlewares carry side effects that must always fire.
+// The error handler is still called once, with the first error.
+func WithShortCircuit[T any](enabled bool) WithOptions[T] {
+	return func(o *options[T]) {
+		o.runAll = !enabled
+	}
+}
@@ -0,0 +1,83 @@
+package channel_test
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/lamlv2305/rok/channel"
+)
+
+func TestShortCircuit(t *testing.T) {
+	tests := []struct {
+		name    string
+		enabled bool
+		// calls made by the middlewares for a value rejected by the first one
+		want []string
+	}{
+		{name: "short circuit", enabled: true, want: []string{"reject"}},
+		{name: "run all", enabled: false, want: []string{"reject", "pass", "split", "after", "after"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls []string
+			record := func(name string, accept bool) channel.Middleware[int] {
+				return func(int) bool {
+					calls = append(calls, name)
+					return accept
+				}
+			}
+
+			var dropped []error
+			c, err := channel.New(4,
+				channel.WithShortCircuit[int](tt.enabled),
+				channel.WithErrorHandler(func(_ int, err error) { dropped = append(dropped, err) }),
+				channel.WithStage(
+					channel.Named[int]("reject", record("reject", false)),
+					record("pass", true),
+					channel.Splitter[int](func(v int) []int {
+						calls = append(calls, "split")
+						return []int{v, v + 1}
+					}),
+					record("after", true),
+				),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if err := c.Send(1); !errors.Is(err, channel.ErrDropped) {
+				t.Fatalf("Send returned %v, want ErrDropped", err)
+			}
+
+			if !slices.Equal(calls, tt.want) {
+				t.Fatalf("called %v, want %v", calls, tt.want)
+			}
+
+			var step *channel.StepError
+			if len(dropped) != 1 || !errors.As(dropped[0], &step) || step.Step != "reject" {
+				t.Fatalf("reported %v, want the error of reject once", dropped)
+			}
+
+			if c.Len() != 0 {
+				t.Fatalf("buffered %d values of a rejected value", c.Len())
+			}
+
+			// a value every middleware accepts goes through both modes the same way
+			calls, dropped = nil, nil
+			c, err = channel.New(4,
+				channel.WithShortCircuit[int](tt.enabled),
+				channel.WithErrorHandler(func(_ int, err error) { dropped = append(dropped, err) }),
+				channel.WithStage(record("pass", true), channel.Splitter[int](func(v int) []int { return []int{v, v} }), record("after", true)),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if err := c.Send(1); err != nil || c.Len() != 2 || len(dropped) != 0 {
+				t.Fatalf("accepted value returned %v and buffered %d parts", err, c.Len())
+			}
+		})
+	}
+}
@@ -0,0 +1,271 @@
+package spill
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/lamlv2305/rok/channel"
+	"github.com/lamlv2305/rok/channel/codec"
+)
+
+// headerSize is the offset of the first record, the header holds the offset of the oldest live one
+const headerSize = 8
+
+// compactAt is how many bytes of removed records a File tolerates before rewriting itself
+const compactAt = 1 << 20
+
+// File is a channel.SpillStore backed by one file of length prefixed records: a big endian uint32 size
+// followed by the encoded value. Values are not synced to disk on every Append, call Sync for that.
+// A record cut short by a crash is discarded on Open.
+type File[T any] struct {
+	mu    sync.Mutex
+	path  string
+	file  *os.File
+	codec codec.Codec
+	// offset of the oldest live record and of the end of the last one
+	head  int64
+	tail  int64
+	count int
+}
+
+var _ channel.SpillStore[int] = (*File[int])(nil)
+
+// Open opens or creates the store at path, values already in it are kept
+func Open[T any](path string, codec codec.Codec) (*File[T], error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, err
+	}
+
+	f := &File[T]{path: path, file: file, codec: codec, head: headerSize, tail: headerSize}
+	if err := f.load(); err != nil {
+		_ = file.Close()
+		return nil, fmt.Errorf("spill: open %s: %w", path, err)
+	}
+
+	return f, nil
+}
+
+// load reads the header and counts the live records
+func (f *File[T]) load() error {
+	info, err := f.file.Stat()
+	if err != nil {
+		return err
+	}
+
+	if info.Size() < headerSize {
+		return f.reset()
+	}
+
+	var header [headerSize]byte
+	if _, err := f.file.ReadAt(header[:], 0); err != nil {
+		return err
+	}
+
+	f.head = int64(binary.BigEndian.Uint64(header[:]))
+	if f.head < headerSize || f.head > info.Size() {
+		return errors.New("corrupted header")
+	}
+
+	reader := bufio.NewReader(io.NewSectionReader(f.file, f.head, info.Size()-f.head))
+	f.tail = f.head
+	for {
+		size, err := readRecord(reader, info.Size()-f.tail, nil)
+		if err != nil {
+			break
+		}
+
+		f.tail += size
+		f.count++
+	}
+
+	// drop a trailing partial record
+	return f.file.Truncate(f.tail)
+}
+
+func (f *File[T]) Append(data T) error {
+	payload, err := f.codec.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	record := make([]byte, 4+len(payload))
+	binary.BigEndian.PutUint32(record, uint32(len(payload)))
+	copy(record[4:], payload)
+
+	if _, err := f.file.WriteAt(record, f.tail); err != nil {
+		return err
+	}
+
+	f.tail += int64(len(record))
+	f.count++
+	return nil
+}
+
+func (f *File[T]) Read(n int) ([]T, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	n = min(n, f.count)
+	if n <= 0 {
+		return nil, nil
+	}
+
+	reader := bufio.NewReader(io.NewSectionReader(f.file, f.head, f.tail-f.head))
+	values := make([]T, 0, n)
+	for offset := f.head; len(values) < n; {
+		var data T
+		size, err := readRecord(reader, f.tail-offset, func(payload []byte) error {
+			return f.codec.Unmarshal(payload, &data)
+		})
+		if err != nil {
+			return values, err
+		}
+
+		offset += size
+		values = append(values, data)
+	}
+
+	return values, nil
+}
+
+func (f *File[T]) Truncate(n int) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	n = min(n, f.count)
+	if n <= 0 {
+		return nil
+	}
+
+	if n == f.count {
+		return f.reset()
+	}
+
+	reader := bufio.NewReader(io.NewSectionReader(f.file, f.head, f.tail-f.head))
+	head := f.head
+	for range n {
+		size, err := readRecord(reader, f.tail-head, nil)
+		if err != nil {
+			return err
+		}
+
+		head += size
+	}
+
+	if err := f.writeHead(head); err != nil {
+		return err
+	}
+
+	f.count -= n
+	// only worth it once the removed records outweigh the live ones
+	if f.head-headerSize >= compactAt && f.head-headerSize > f.tail-f.head {
+		return f.compact()
+	}
+
+	return nil
+}
+
+func (f *File[T]) Len() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.count
+}
+
+// Sync commits the stored values to disk
+func (f *File[T]) Sync() error {
+	return f.file.Sync()
+}
+
+func (f *File[T]) Close() error {
+	return f.file.Close()
+}
+
+// reset, compact and writeHead must be called with mu held
+func (f *File[T]) reset() error {
+	if err := f.file.Truncate(headerSize); err != nil {
+		return err
+	}
+
+	f.tail, f.count = headerSize, 0
+	return f.writeHead(headerSize)
+}
+
+// compact rewrites the live records to a new file that replaces the current one,
+// a crash in between leaves the current one intact
+func (f *File[T]) compact() error {
+	live := make([]byte, headerSize+f.tail-f.head)
+	binary.BigEndian.PutUint64(live, headerSize)
+	if _, err := f.file.ReadAt(live[headerSize:], f.head); err != nil {
+		return err
+	}
+
+	tmp := f.path + ".tmp"
+	if err := os.WriteFile(tmp, live, 0o600); err != nil {
+		return err
+	}
+
+	if err := os.Rename(tmp, f.path); err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(f.path, os.O_RDWR, 0o600)
+	if err != nil {
+		return err
+	}
+
+	_ = f.file.Close()
+	f.file = file
+	f.head, f.tail = headerSize, int64(len(live))
+	return nil
+}
+
+func (f *File[T]) writeHead(head int64) error {
+	var header [headerSize]byte
+	binary.BigEndian.PutUint64(header[:], uint64(head))
+	if _, err := f.file.WriteAt(header[:], 0); err != nil {
+		return err
+	}
+
+	f.head = head
+	return nil
+}
+
+// errCorrupted is returned for a record claiming more bytes than left in the file
+var errCorrupted = errors.New("spill: corrupted record")
+
+// readRecord reads one record out of the remaining bytes of the file, passes its payload
+// to decode when set and returns its size on disk
+func readRecord(reader *bufio.Reader, remaining int64, decode func([]byte) error) (int64, error) {
+	var prefix [4]byte
+	if _, err := io.ReadFull(reader, prefix[:]); err != nil {
+		return 0, err
+	}
+
+	// a size from a torn or corrupted prefix must not decide how much is allocated
+	size := int64(binary.BigEndian.Uint32(prefix[:]))
+	if size > remaining-int64(len(prefix)) {
+		return 0, errCorrupted
+	}
+
+	payload := make([]byte, size)
+	if _, err := io.ReadFull(reader, payload); err != nil {
+		return 0, err
+	}
+
+	if decode != nil {
+		if err := decode(payload); err != nil {
+			return 0, err
+		}
+	}
+
+	return int64(4 + len(payload)), nil
+}
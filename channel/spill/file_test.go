@@ -0,0 +1,88 @@
+package spill
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/lamlv2305/rok/channel/codec"
+)
+
+func TestFileRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "values.spill")
+	f, err := Open[string](path, codec.JSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, v := range []string{"a", "b", "c"} {
+		if err := f.Append(v); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := f.Truncate(1); err != nil {
+		t.Fatal(err)
+	}
+	_ = f.Close()
+
+	reopened, err := Open[string](path, codec.JSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reopened.Close()
+
+	got, err := reopened.Read(10)
+	if err != nil || len(got) != 2 || got[0] != "b" || got[1] != "c" {
+		t.Fatalf("Read returned %v, %v, want [b c]", got, err)
+	}
+}
+
+func TestFileCorruptedSize(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "values.spill")
+	f, err := Open[string](path, codec.JSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := f.Append("a"); err != nil {
+		t.Fatal(err)
+	}
+
+	// a prefix claiming 4GB would otherwise be allocated as is
+	var prefix [4]byte
+	binary.BigEndian.PutUint32(prefix[:], 1<<32-1)
+	if _, err := f.file.WriteAt(prefix[:], f.tail); err != nil {
+		t.Fatal(err)
+	}
+	_ = f.Close()
+
+	reopened, err := Open[string](path, codec.JSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reopened.Close()
+
+	if reopened.Len() != 1 {
+		t.Fatalf("Len %d after a corrupted trailing record, want 1", reopened.Len())
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if info.Size() != reopened.tail {
+		t.Fatalf("file is %d bytes, want the corrupted record dropped at %d", info.Size(), reopened.tail)
+	}
+
+	// the size is checked before anything is allocated
+	record := bufio.NewReader(bytes.NewReader(prefix[:]))
+	if _, err := readRecord(record, 1<<10, nil); !errors.Is(err, errCorrupted) {
+		t.Fatalf("readRecord returned %v, want errCorrupted", err)
+	}
+}
@@ -0,0 +1,7 @@
+// Package spill stores the values a channel.Channel could not keep in memory, see channel.WithSpill.
+//
+//	store, err := spill.Open[Order]("orders.spill", codec.JSON)
+//	ch, err := channel.New[Order](1024, channel.WithSpill[Order](store))
+//
+// Values spilled before a restart are replayed first by the next channel opened on the same store.
+package spill
@@ -0,0 +1,86 @@
+package channel
+
+import (
+	"context"
+	"fmt"
+)
+
+// WithSpill makes a full Channel append values to store instead of applying the overflow policy,
+// they are moved back into the buffer as it frees up. Values stay FIFO: once something is spilled,
+// every new value is spilled too until the store is empty again.
+// A Channel created on a store that still holds values, e.g. after a restart, receives them first.
+// Only the spilled values survive a restart, the in-memory buffer does not.
+// Send returns the error of the store. A value the store fails to hand back, e.g. one that no longer
+// decodes after a restart, is removed from it so that the values behind it are not stuck, and reported
+// to WithErrorHandler and WithDeadLetter as the zero value with ErrUnreadable.
+// Len counts the spilled values, CloseNow discards them.
+func WithSpill[T any](store SpillStore[T]) WithOptions[T] {
+	return func(o *options[T]) {
+		o.spill = store
+	}
+}
+
+// SpillStore is a FIFO of values, oldest first, spill.File stores them in a file.
+// A Channel calls it with its own lock held, so an implementation needs no locking of its own
+// when it is used by a single Channel.
+type SpillStore[T any] interface {
+	// Append adds data after every stored value
+	Append(data T) error
+	// Read returns up to n of the oldest values without removing them
+	Read(n int) ([]T, error)
+	// Truncate removes the n oldest values
+	Truncate(n int) error
+	// Len is how many values are stored
+	Len() int
+}
+
+// spilling, spilled and refill must be called with mu held
+func (c *Channel[T]) spilling() bool {
+	return c.options.spill != nil && (c.queue.len() >= c.size || c.options.spill.Len() > 0)
+}
+
+func (c *Channel[T]) spilled() int {
+	if c.options.spill == nil {
+		return 0
+	}
+
+	return c.options.spill.Len()
+}
+
+// refill moves spilled values back into the free part of the buffer, it returns the reporting
+// of the values it could not read, to call once mu is released
+func (c *Channel[T]) refill() func() {
+	var unreadable []error
+	for c.spilled() > 0 && c.queue.len() < c.size {
+		values, err := c.options.spill.Read(c.size - c.queue.len())
+		if len(values) == 0 {
+			// the oldest value cannot be read back, it is discarded rather than blocking the ones behind it
+			if err == nil || c.options.spill.Truncate(1) != nil {
+				break
+			}
+
+			unreadable = append(unreadable, fmt.Errorf("%w: %w", ErrUnreadable, err))
+			continue
+		}
+
+		// pushed only once removed from the store, so that a value is never received twice
+		if err := c.options.spill.Truncate(len(values)); err != nil {
+			break
+		}
+
+		for idx := range values {
+			c.queue.push(values[idx])
+		}
+	}
+
+	if len(unreadable) == 0 {
+		return noop
+	}
+
+	return func() {
+		var zero T
+		for _, err := range unreadable {
+			c.options.drop(context.Background(), zero, err)
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package channel_test
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/lamlv2305/rok/channel"
+	"github.com/lamlv2305/rok/channel/codec"
+	"github.com/lamlv2305/rok/channel/spill"
+)
+
+func TestSpill(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "values.spill")
+	open := func() (*channel.Channel[int], *spill.File[int]) {
+		store, err := spill.Open[int](path, codec.JSON)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		c, err := channel.New(2, channel.WithSpill[int](store))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return c, store
+	}
+
+	c, store := open()
+	for idx := range 5 {
+		if err := c.Send(idx); err != nil {
+			t.Fatalf("Send(%d) on a full buffer returned %v, want it spilled", idx, err)
+		}
+	}
+
+	if store.Len() != 3 || c.Len() != 5 {
+		t.Fatalf("spilled %d of %d values, want 3 of 5", store.Len(), c.Len())
+	}
+
+	// draining moves the spilled values back in order
+	for want := range 5 {
+		if got, ok := c.Recv(); !ok || got != want {
+			t.Fatalf("Recv got %d, %v, want %d", got, ok, want)
+		}
+	}
+
+	// 5 and 6 fill the buffer again, 7 and 8 spill and are the only ones surviving a restart
+	for idx := 5; idx < 9; idx++ {
+		if err := c.Send(idx); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := store.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	restarted, store := open()
+	defer store.Close()
+
+	_ = restarted.Close()
+	var got []int
+	for v := range restarted.Iter() {
+		got = append(got, v)
+	}
+
+	if len(got) != 2 || got[0] != 7 || got[1] != 8 {
+		t.Fatalf("after restart got %v, want the spilled [7 8]", got)
+	}
+}
+
+func TestSpillUnreadable(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "values.spill")
+	// a value that does not decode to int, e.g. written by an older version of the type
+	written, err := spill.Open[any](path, codec.JSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, v := range []any{1, "two", 3} {
+		if err := written.Append(v); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := written.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	store, err := spill.Open[int](path, codec.JSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	var dropped []error
+	c, err := channel.New(1,
+		channel.WithSpill[int](store),
+		channel.WithErrorHandler(func(_ int, err error) { dropped = append(dropped, err) }),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := c.Send(4); err != nil {
+		t.Fatal(err)
+	}
+
+	_ = c.Close()
+	var got []int
+	for v := range c.Iter() {
+		got = append(got, v)
+	}
+
+	if len(got) != 3 || got[0] != 1 || got[1] != 3 || got[2] != 4 {
+		t.Fatalf("got %v, want [1 3 4] past the unreadable value", got)
+	}
+
+	if len(dropped) != 1 || !errors.Is(dropped[0], channel.ErrUnreadable) {
+		t.Fatalf("reported %v, want one ErrUnreadable", dropped)
+	}
+}
@@ -0,0 +1,17 @@
+package channel
+
+// Splitter turns one value into zero or more values, each one goes through
+// the middlewares registered after it on its own. Returning nothing drops the value.
+type Splitter[T any] func(T) []T
+
+func WithSplitter[T any](splitters ...Splitter[T]) WithOptions[T] {
+	return func(o *options[T]) {
+		for idx := range splitters {
+			o.pipeline.add(splitters[idx].step())
+		}
+	}
+}
+
+func (s Splitter[T]) step() step[T] {
+	return step[T]{split: s}
+}
@@ -0,0 +1,55 @@
+package channel_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/lamlv2305/rok/channel"
+)
+
+func TestSplitter(t *testing.T) {
+	var downstream []int
+	c, err := channel.New(8,
+		channel.WithMiddleware(func(v int) bool { return v != 0 }),
+		channel.WithSplitter(func(v int) []int {
+			var parts []int
+			for idx := 1; idx <= v; idx++ {
+				parts = append(parts, v*10+idx)
+			}
+
+			return parts
+		}),
+		channel.WithMiddleware(func(v int) bool {
+			downstream = append(downstream, v)
+			// each part is decided on its own
+			return v != 32
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := c.Send(3); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(downstream) != 3 {
+		t.Fatalf("downstream ran %d times, want 3", len(downstream))
+	}
+
+	for _, want := range []int{31, 33} {
+		if got, _ := c.Recv(); got != want {
+			t.Fatalf("Recv got %d, want %d", got, want)
+		}
+	}
+
+	// no part at all drops the value
+	downstream = nil
+	if err := c.Send(-1); !errors.Is(err, channel.ErrDropped) {
+		t.Fatalf("Send of a value split into nothing returned %v, want ErrDropped", err)
+	}
+
+	if len(downstream) != 0 || c.Len() != 0 {
+		t.Fatalf("downstream ran %d times and %d values are buffered, want none", len(downstream), c.Len())
+	}
+}
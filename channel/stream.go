@@ -0,0 +1,179 @@
+package channel
+
+import "context"
+
+// Map applies f to every value of in, the output is closed once in is closed
+func Map[A, B any](in <-chan A, f func(A) B) <-chan B {
+	return MapContext(context.Background(), in, f)
+}
+
+// MapContext is Map that also stops, and closes the output, once ctx is done
+func MapContext[A, B any](ctx context.Context, in <-chan A, f func(A) B) <-chan B {
+	out := make(chan B)
+	go func() {
+		defer close(out)
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case data, ok := <-in:
+				if !ok {
+					return
+				}
+
+				select {
+				case out <- f(data):
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return out
+}
+
+// Filter keeps the values of in matching pred, the output is closed once in is closed.
+// pred has the shape of a Middleware, so any Middleware can be used as a stream filter;
+// a Pipeline can be used too through its Run method.
+func Filter[T any](in <-chan T, pred func(T) bool) <-chan T {
+	return FilterContext(context.Background(), in, pred)
+}
+
+// FilterContext is Filter that also stops, and closes the output, once ctx is done
+func FilterContext[T any](ctx context.Context, in <-chan T, pred func(T) bool) <-chan T {
+	out := make(chan T)
+	go func() {
+		defer close(out)
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case data, ok := <-in:
+				if !ok {
+					return
+				}
+
+				if !pred(data) {
+					continue
+				}
+
+				select {
+				case out <- data:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return out
+}
+
+// Reduce folds every value of in into init, it blocks until in is closed
+func Reduce[T, R any](in <-chan T, init R, f func(R, T) R) R {
+	result, _ := ReduceContext(context.Background(), in, init, f)
+	return result
+}
+
+// ReduceContext is Reduce that gives up once ctx is done, returning what was folded so far and ctx.Err()
+func ReduceContext[T, R any](ctx context.Context, in <-chan T, init R, f func(R, T) R) (R, error) {
+	acc := init
+	for {
+		select {
+		case <-ctx.Done():
+			return acc, ctx.Err()
+		case data, ok := <-in:
+			if !ok {
+				return acc, nil
+			}
+
+			acc = f(acc, data)
+		}
+	}
+}
+
+// Take emits the first n values of in then closes its output and stops reading in.
+// The caller owns in: its producer is not told to stop and must not block forever on it.
+func Take[T any](in <-chan T, n int) <-chan T {
+	out := make(chan T)
+	go func() {
+		defer close(out)
+
+		for taken := 0; taken < n; taken++ {
+			data, ok := <-in
+			if !ok {
+				return
+			}
+
+			out <- data
+		}
+	}()
+
+	return out
+}
+
+// Skip drops the first n values of in and emits the others, the output is closed once in is closed
+func Skip[T any](in <-chan T, n int) <-chan T {
+	out := make(chan T)
+	go func() {
+		defer close(out)
+
+		skipped := 0
+		for data := range in {
+			if skipped < n {
+				skipped++
+				continue
+			}
+
+			out <- data
+		}
+	}()
+
+	return out
+}
+
+type WindowMode int
+
+const (
+	// Tumbling emits consecutive non-overlapping windows, the last one may be shorter
+	Tumbling WindowMode = iota
+	// Sliding emits a window of the last size values for every value once size values were seen
+	Sliding
+)
+
+// Window groups the values of in by size, each emitted slice is owned by the receiver.
+// The output is closed once in is closed.
+func Window[T any](in <-chan T, size int, mode WindowMode) <-chan []T {
+	size = max(size, 1)
+	out := make(chan []T)
+	go func() {
+		defer close(out)
+
+		window := make([]T, 0, size)
+		for data := range in {
+			window = append(window, data)
+			if len(window) < size {
+				continue
+			}
+
+			emitted := make([]T, size)
+			copy(emitted, window)
+			out <- emitted
+
+			if mode == Sliding {
+				window = append(window[:0], window[1:]...)
+			} else {
+				window = window[:0]
+			}
+		}
+
+		if mode == Tumbling && len(window) > 0 {
+			out <- window
+		}
+	}()
+
+	return out
+}
@@ -0,0 +1,216 @@
+package channel_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/lamlv2305/rok/channel"
+)
+
+// source returns a closed channel holding values
+func source[T any](values ...T) <-chan T {
+	in := make(chan T, len(values))
+	for _, v := range values {
+		in <- v
+	}
+
+	close(in)
+	return in
+}
+
+// collect reads out until it is closed, failing the test if that takes too long
+func collect[T any](t *testing.T, out <-chan T) []T {
+	t.Helper()
+
+	var got []T
+	timeout := time.After(time.Second)
+	for {
+		select {
+		case v, ok := <-out:
+			if !ok {
+				return got
+			}
+
+			got = append(got, v)
+		case <-timeout:
+			t.Fatalf("output not closed, got %v so far", got)
+		}
+	}
+}
+
+func equal[T comparable](a, b []T) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for idx := range a {
+		if a[idx] != b[idx] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func TestMap(t *testing.T) {
+	got := collect(t, channel.Map(source(1, 2, 3), func(v int) string { return string(rune('a' + v - 1)) }))
+	if !equal(got, []string{"a", "b", "c"}) {
+		t.Fatalf("got %v", got)
+	}
+
+	if got := collect(t, channel.Map(source[int](), func(v int) int { return v })); len(got) != 0 {
+		t.Fatalf("got %v from an empty input", got)
+	}
+}
+
+func TestFilter(t *testing.T) {
+	var even channel.Middleware[int] = func(v int) bool { return v%2 == 0 }
+	if got := collect(t, channel.Filter(source(1, 2, 3, 4), even)); !equal(got, []int{2, 4}) {
+		t.Fatalf("got %v", got)
+	}
+
+	p := channel.NewPipeline[int]().Use(even, func(v int) bool { return v > 2 })
+	if got := collect(t, channel.Filter(source(1, 2, 3, 4), p.Run)); !equal(got, []int{4}) {
+		t.Fatalf("got %v through a pipeline", got)
+	}
+}
+
+func TestReduceBlocksUntilClosed(t *testing.T) {
+	in := make(chan int)
+	result := make(chan int, 1)
+	go func() {
+		result <- channel.Reduce(in, 0, func(acc, v int) int { return acc + v })
+	}()
+
+	in <- 1
+	in <- 2
+	select {
+	case got := <-result:
+		t.Fatalf("Reduce returned %d before the input was closed", got)
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	close(in)
+	select {
+	case got := <-result:
+		if got != 3 {
+			t.Fatalf("Reduce returned %d, want 3", got)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Reduce did not return once the input was closed")
+	}
+}
+
+func TestStreamContext(t *testing.T) {
+	// never closed: only the context stops the combinators
+	in := make(chan int)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	mapped := channel.MapContext(ctx, in, func(v int) int { return v })
+	filtered := channel.FilterContext(ctx, in, func(int) bool { return true })
+	reduced := make(chan error, 1)
+	go func() {
+		_, err := channel.ReduceContext(ctx, in, 0, func(acc, v int) int { return acc + v })
+		reduced <- err
+	}()
+
+	cancel()
+	collect(t, mapped)
+	collect(t, filtered)
+
+	select {
+	case err := <-reduced:
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("ReduceContext returned %v, want context.Canceled", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("ReduceContext did not return once the context was cancelled")
+	}
+}
+
+func TestTake(t *testing.T) {
+	tests := []struct {
+		name string
+		in   []int
+		n    int
+		want []int
+	}{
+		{name: "prefix", in: []int{1, 2, 3, 4}, n: 2, want: []int{1, 2}},
+		{name: "n larger than the stream", in: []int{1, 2}, n: 5, want: []int{1, 2}},
+		{name: "n is zero", in: []int{1, 2}, n: 0},
+		{name: "empty input", n: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := collect(t, channel.Take(source(tt.in...), tt.n)); !equal(got, tt.want) {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTakeStopsReading(t *testing.T) {
+	in := source(1, 2, 3, 4)
+	collect(t, channel.Take(in, 2))
+
+	if got := collect(t, in); !equal(got, []int{3, 4}) {
+		t.Fatalf("left %v in the source, want [3 4]", got)
+	}
+}
+
+func TestSkip(t *testing.T) {
+	tests := []struct {
+		name string
+		in   []int
+		n    int
+		want []int
+	}{
+		{name: "suffix", in: []int{1, 2, 3, 4}, n: 2, want: []int{3, 4}},
+		{name: "n larger than the stream", in: []int{1, 2}, n: 5},
+		{name: "n is zero", in: []int{1, 2}, n: 0, want: []int{1, 2}},
+		{name: "empty input", n: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := collect(t, channel.Skip(source(tt.in...), tt.n)); !equal(got, tt.want) {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWindow(t *testing.T) {
+	tests := []struct {
+		name string
+		in   []int
+		size int
+		mode channel.WindowMode
+		want [][]int
+	}{
+		{name: "tumbling", in: []int{1, 2, 3, 4, 5}, size: 2, mode: channel.Tumbling, want: [][]int{{1, 2}, {3, 4}, {5}}},
+		{name: "sliding", in: []int{1, 2, 3, 4}, size: 3, mode: channel.Sliding, want: [][]int{{1, 2, 3}, {2, 3, 4}}},
+		{name: "sliding shorter than size", in: []int{1, 2}, size: 3, mode: channel.Sliding},
+		{name: "size larger than the stream", in: []int{1, 2}, size: 5, mode: channel.Tumbling, want: [][]int{{1, 2}}},
+		{name: "size zero is one", in: []int{1, 2}, size: 0, mode: channel.Tumbling, want: [][]int{{1}, {2}}},
+		{name: "empty input", size: 2, mode: channel.Tumbling},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := collect(t, channel.Window(source(tt.in...), tt.size, tt.mode))
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+
+			for idx := range got {
+				if !equal(got[idx], tt.want[idx]) {
+					t.Fatalf("got %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
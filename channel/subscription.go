@@ -0,0 +1,86 @@
+package channel
+
+import "github.com/google/uuid"
+
+// Subscription receives the values published by a Fanout
+type Subscription[T any] struct {
+	ch       chan T
+	replayed int
+	close    func()
+}
+
+func (s *Subscription[T]) C() <-chan T {
+	return s.ch
+}
+
+// Replayed is how many of the first values of C come from the replay buffer
+func (s *Subscription[T]) Replayed() int {
+	return s.replayed
+}
+
+// Close unregisters the subscription and closes C once no publish is writing to it,
+// values already buffered can still be received. Calling it again does nothing.
+func (s *Subscription[T]) Close() {
+	s.close()
+}
+
+// Subscribe registers a subscriber with room for buffer live values, ignore filters what it receives.
+// With WithReplay the replayed values are already in C when Subscribe returns.
+func (f *Fanout[T]) Subscribe(buffer int, ignore func(T) bool) *Subscription[T] {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var replay []T
+	if f.relay != nil {
+		relayedData := f.relay.Get()
+		for idx := range relayedData {
+			if ignore != nil && ignore(relayedData[idx].(T)) {
+				continue
+			}
+
+			replay = append(replay, f.options.copyOf(relayedData[idx].(T)))
+		}
+	}
+
+	ch := make(chan T, buffer+len(replay))
+	for idx := range replay {
+		ch <- replay[idx]
+	}
+
+	id := uuid.New().String()
+	sub := &SingleData[T]{
+		ch:     ch,
+		done:   make(chan struct{}),
+		ignore: ignore,
+	}
+	f.data.Set(id, sub)
+	f.refresh()
+
+	return &Subscription[T]{
+		ch:       ch,
+		replayed: len(replay),
+		close: func() {
+			// release publishers blocked on this subscriber before waiting for the write lock
+			sub.once.Do(func() {
+				close(sub.done)
+			})
+
+			f.mu.Lock()
+			_, ok := f.data.Get(id)
+			if ok {
+				f.data.Remove(id)
+				f.refresh()
+			}
+			f.mu.Unlock()
+
+			if !ok {
+				return
+			}
+
+			// publishers still holding an old snapshot see done once they get the lock
+			sub.mu.Lock()
+			defer sub.mu.Unlock()
+			close(ch)
+		},
+	}
+}
@@ -0,0 +1,61 @@
+package channel
+
+import (
+	"context"
+	"time"
+)
+
+// WithTimeout bounds every middleware call to d, a slower middleware drops the value with ErrTimeout.
+//
+// Each call then runs in its own goroutine. Context-aware middlewares get a context cancelled after d,
+// with ErrTimeout as its cause, and should return early, the other ones cannot be interrupted: a middleware that blocks forever
+// leaks its goroutine even though the pipeline moved on.
+func WithTimeout[T any](d time.Duration) WithOptions[T] {
+	return func(o *options[T]) {
+		o.timeout = d
+	}
+}
+
+type outcome[T any] struct {
+	result T
+	err    error
+}
+
+func (o *options[T]) invokeTimeout(parent context.Context, idx int, data T) (T, error) {
+	// timed with the clock of the options rather than context.WithTimeout, so WithClock applies
+	ctx, cancel := context.WithCancelCause(parent)
+	defer cancel(nil)
+
+	timer := o.clock.AfterFunc(o.timeout, func() {
+		cancel(ErrTimeout)
+	})
+	defer timer.Stop()
+
+	// buffered so a late middleware can still finish and exit
+	done := make(chan outcome[T], 1)
+	// the goroutine gets copies rather than o, which would otherwise move to the heap for every caller
+	fn, panicHandler, running := o.pipeline.steps[idx].fn, o.panicHandler, o.running
+	if running != nil {
+		running.Add(1)
+	}
+
+	go func() {
+		if running != nil {
+			defer running.Done()
+		}
+
+		result, err := invoke(ctx, fn, panicHandler, data)
+		done <- outcome[T]{result: result, err: err}
+	}()
+
+	select {
+	case out := <-done:
+		return out.result, out.err
+	case <-ctx.Done():
+		if err := parent.Err(); err != nil {
+			return data, err
+		}
+
+		return data, ErrTimeout
+	}
+}
@@ -0,0 +1,60 @@
+package channel_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/lamlv2305/rok/channel"
+)
+
+func TestTimeout(t *testing.T) {
+	tests := []struct {
+		name string
+		opt  channel.WithOptions[int]
+	}{
+		{name: "plain middleware", opt: channel.WithMiddleware(func(int) bool {
+			time.Sleep(100 * time.Millisecond)
+			return true
+		})},
+		{name: "context-aware middleware", opt: channel.WithCtxMiddleware(func(ctx context.Context, _ int) bool {
+			select {
+			case <-ctx.Done():
+				return false
+			case <-time.After(100 * time.Millisecond):
+				return true
+			}
+		})},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var reported error
+			c, err := channel.New(1,
+				channel.WithTimeout[int](10*time.Millisecond),
+				channel.WithErrorHandler(func(_ int, err error) { reported = err }),
+				tt.opt,
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			start := time.Now()
+			if err := c.Send(1); !errors.Is(err, channel.ErrTimeout) {
+				t.Fatalf("Send returned %v, want ErrTimeout", err)
+			}
+
+			if elapsed := time.Since(start); elapsed >= 100*time.Millisecond {
+				t.Fatalf("Send took %s, want it to give up after the timeout", elapsed)
+			}
+
+			if !errors.Is(reported, channel.ErrTimeout) {
+				t.Fatalf("error handler got %v, want ErrTimeout", reported)
+			}
+
+			_ = c.Close()
+			c.Wait()
+		})
+	}
+}
@@ -0,0 +1,24 @@
+package channel
+
+import "context"
+
+// Tracer starts one span per value going through the pipeline, name is the one set by WithName.
+// The returned context is the one given to context-aware middlewares.
+// channel/otel provides an OpenTelemetry implementation.
+type Tracer interface {
+	Start(ctx context.Context, name string) (context.Context, Span)
+}
+
+type Span interface {
+	// Step is called after each middleware, name is empty for unnamed ones
+	Step(name string, dropped bool)
+	// End is called once the value went through the pipeline, err is why it was dropped
+	End(err error)
+}
+
+// WithTracer traces each value, use SendContext so the span has the caller's span as parent
+func WithTracer[T any](tracer Tracer) WithOptions[T] {
+	return func(o *options[T]) {
+		o.tracer = tracer
+	}
+}
@@ -0,0 +1,28 @@
+package channel
+
+import "context"
+
+// Transform is a middleware that can rewrite the value it receives.
+// Returning false drops the value, same as Middleware.
+type Transform[T any] func(T) (T, bool)
+
+func WithTransform[T any](transforms ...Transform[T]) WithOptions[T] {
+	return func(o *options[T]) {
+		for idx := range transforms {
+			o.pipeline.add(transforms[idx].step())
+		}
+	}
+}
+
+func (t Transform[T]) step() step[T] {
+	return step[T]{
+		fn: func(_ context.Context, data T) (T, error) {
+			result, ok := t(data)
+			if !ok {
+				return data, ErrDropped
+			}
+
+			return result, nil
+		},
+	}
+}
@@ -0,0 +1,21 @@
+package channel
+
+import "time"
+
+// WithTTL drops a value on receive when more than d elapsed since start(value), the value
+// is reported with ErrExpired to the error handler and the dead letter channel.
+// The check happens on the receive side since that is where queueing delay piles up.
+func WithTTL[T any](d time.Duration, start func(T) time.Time) WithOptions[T] {
+	return func(o *options[T]) {
+		o.ttl = d
+		o.ttlStart = start
+	}
+}
+
+func (c *Channel[T]) expired(data T) bool {
+	if c.options.ttlStart == nil {
+		return false
+	}
+
+	return c.options.clock.Now().Sub(c.options.ttlStart(data)) > c.options.ttl
+}
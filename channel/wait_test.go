@@ -0,0 +1,99 @@
+package channel
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"go.uber.org/goleak"
+)
+
+func TestWaitLeavesNoGoroutine(t *testing.T) {
+	slow := func(ctx context.Context, _ int) bool {
+		select {
+		case <-ctx.Done():
+		case <-time.After(10 * time.Millisecond):
+		}
+
+		return true
+	}
+
+	t.Run("channel", func(t *testing.T) {
+		defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+
+		ctx, cancel := context.WithCancel(context.Background())
+		c, err := New(4,
+			WithContext[int](ctx),
+			WithTimeout[int](time.Millisecond),
+			WithCtxMiddleware(slow),
+			WithSideEffect(func(int) { time.Sleep(5 * time.Millisecond) }),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// the goroutine of C holds a value nobody reads
+		_ = c.C()
+		for idx := range 4 {
+			_ = c.Send(idx)
+		}
+
+		cancel()
+		_ = c.CloseNow()
+		within(t, time.Second, c.Wait)
+	})
+
+	t.Run("channel drained through C", func(t *testing.T) {
+		defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+
+		c, err := New[int](4)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for idx := range 3 {
+			_ = c.Send(idx)
+		}
+
+		_ = c.Close()
+		for range c.C() {
+		}
+
+		within(t, time.Second, c.Wait)
+	})
+
+	for _, workers := range []int{0, 4} {
+		t.Run(fmt.Sprintf("fanout with %d workers", workers), func(t *testing.T) {
+			defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+
+			ctx, cancel := context.WithCancel(context.Background())
+			f := NewFanout(
+				WithContext[int](ctx),
+				WithWorkers[int](workers),
+				WithTimeout[int](time.Millisecond),
+				WithCtxMiddleware(slow),
+				WithSideEffect(func(int) { time.Sleep(5 * time.Millisecond) }),
+			)
+
+			sub := f.Subscribe(16, nil)
+			defer sub.Close()
+
+			for idx := range 8 {
+				f.Send(idx)
+			}
+
+			cancel()
+			select {
+			case <-f.Done():
+			case <-time.After(time.Second):
+				t.Fatal("not stopped after the context was cancelled")
+			}
+
+			if err := f.SendContext(context.Background(), 1); !errors.Is(err, ErrClosed) {
+				t.Fatalf("Send after Stop returned %v, want ErrClosed", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package channel
+
+import "context"
+
+// When runs stage only for values matching pred, it keeps the name of stage.
+// A value for which pred is false always passes unchanged, When never drops it.
+func When[T any](pred func(T) bool, stage Stage[T]) Stage[T] {
+	return guard(pred, true, stage)
+}
+
+// Unless runs stage only for values not matching pred, a value matching pred always passes
+func Unless[T any](pred func(T) bool, stage Stage[T]) Stage[T] {
+	return guard(pred, false, stage)
+}
+
+// guard runs stage when pred returns want
+func guard[T any](pred func(T) bool, want bool, stage Stage[T]) Stage[T] {
+	return stageFunc[T](func() step[T] {
+		inner := stage.step()
+		guarded := step[T]{name: inner.name, clock: inner.clock}
+
+		if inner.split != nil {
+			guarded.split = func(data T) []T {
+				if pred(data) != want {
+					return []T{data}
+				}
+
+				return inner.split(data)
+			}
+
+			return guarded
+		}
+
+		guarded.fn = func(ctx context.Context, data T) (T, error) {
+			if pred(data) != want {
+				return data, nil
+			}
+
+			return inner.fn(ctx, data)
+		}
+
+		return guarded
+	})
+}
@@ -0,0 +1,240 @@
+package channel
+
+import (
+	"context"
+	"sync"
+)
+
+// WithWorkers runs the middlewares of each value on a pool of n goroutines instead of the caller's.
+// Every worker takes a value end-to-end: middlewares, then delivery.
+func WithWorkers[T any](n int) WithOptions[T] {
+	return func(o *options[T]) {
+		o.workers = n
+	}
+}
+
+// WithOrdered makes the worker pool deliver values in the order they were sent.
+// A value that finished early waits for the ones sent before it, trading throughput for order.
+// Without it values are delivered as soon as their worker is done.
+func WithOrdered[T any](ordered bool) WithOptions[T] {
+	return func(o *options[T]) {
+		o.ordered = ordered
+	}
+}
+
+// WithKeyedOrder makes the worker pool process values sharing a key, as sent, one at a time in the order
+// they were sent, while values of different keys still run concurrently. WithOrdered is then ignored.
+// A value whose key is busy waits in a backlog instead of the queue, so Send does not block on it.
+// A key is forgotten as soon as its backlog is empty, memory follows the keys in flight.
+func WithKeyedOrder[T any, K comparable](key func(T) K) WithOptions[T] {
+	return func(o *options[T]) {
+		o.orderKey = func(data T) any {
+			return key(data)
+		}
+	}
+}
+
+type job[T any] struct {
+	ctx  context.Context
+	data T
+	seq  uint64
+}
+
+type pool[T any] struct {
+	// mu keeps seq in the same order as the jobs queue, so a worker never waits
+	// for a value that has not been picked up yet
+	mu     sync.Mutex
+	jobs   chan job[T]
+	seq    uint64
+	closed bool
+	wg     sync.WaitGroup
+	// closed and replaced once a worker takes a job, so submitters wait for room without holding mu
+	changed chan struct{}
+	waiting bool
+
+	ordered bool
+	turn    *sync.Cond
+	next    uint64
+
+	// values waiting for the job of the same key in flight, never locked before mu
+	keysMu sync.Mutex
+	key    func(T) any
+	keys   map[any][]job[T]
+
+	process func(context.Context, T, func(T)) error
+	deliver func(T)
+}
+
+func newPool[T any](n int, ordered bool, key func(T) any, process func(context.Context, T, func(T)) error, deliver func(T)) *pool[T] {
+	p := &pool[T]{
+		jobs:    make(chan job[T], n),
+		ordered: ordered && key == nil,
+		key:     key,
+		keys:    map[any][]job[T]{},
+		changed: make(chan struct{}),
+		turn:    sync.NewCond(&sync.Mutex{}),
+		process: process,
+		deliver: deliver,
+	}
+
+	p.wg.Add(n)
+	for i := 0; i < n; i++ {
+		go p.work()
+	}
+
+	return p
+}
+
+// submit blocks while every worker is busy and the queue is full, until ctx is done
+func (p *pool[T]) submit(ctx context.Context, data T) error {
+	for {
+		p.mu.Lock()
+		if p.closed {
+			p.mu.Unlock()
+			return ErrClosed
+		}
+
+		j := job[T]{ctx: ctx, data: data, seq: p.seq}
+		if p.key != nil && p.wait(j) {
+			p.mu.Unlock()
+			return nil
+		}
+
+		select {
+		case p.jobs <- j:
+			p.seq++
+			p.mu.Unlock()
+			return nil
+		default:
+		}
+
+		// nothing was queued behind j, that takes mu
+		if p.key != nil {
+			p.forget(j)
+		}
+
+		changed := p.room()
+		p.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-changed:
+		}
+	}
+}
+
+// room returns the channel closed by the next notify, room and notify must be called with mu held
+func (p *pool[T]) room() <-chan struct{} {
+	p.waiting = true
+	return p.changed
+}
+
+// notify only allocates a new changed channel when somebody is waiting on the current one
+func (p *pool[T]) notify() {
+	if p.waiting {
+		close(p.changed)
+		p.changed = make(chan struct{})
+		p.waiting = false
+	}
+}
+
+// stop refuses new values, then waits until every queued and in-flight value
+// went through the middlewares and was delivered
+func (p *pool[T]) stop() {
+	p.mu.Lock()
+	if !p.closed {
+		p.closed = true
+		close(p.jobs)
+		p.notify()
+	}
+	p.mu.Unlock()
+
+	p.wg.Wait()
+}
+
+func (p *pool[T]) work() {
+	defer p.wg.Done()
+
+	var results []T
+	collect := func(data T) {
+		results = append(results, data)
+	}
+
+	for j := range p.jobs {
+		p.mu.Lock()
+		p.notify()
+		p.mu.Unlock()
+
+		if p.key != nil {
+			p.processKey(j)
+			continue
+		}
+
+		if !p.ordered {
+			_ = p.process(j.ctx, j.data, p.deliver)
+			continue
+		}
+
+		results = results[:0]
+		_ = p.process(j.ctx, j.data, collect)
+
+		p.turn.L.Lock()
+		for p.next != j.seq {
+			p.turn.Wait()
+		}
+
+		for idx := range results {
+			p.deliver(results[idx])
+		}
+
+		p.next++
+		p.turn.Broadcast()
+		p.turn.L.Unlock()
+	}
+}
+
+// wait queues j behind the job of the same key in flight and reports whether there was one,
+// otherwise j becomes the job in flight for its key
+func (p *pool[T]) wait(j job[T]) bool {
+	p.keysMu.Lock()
+	defer p.keysMu.Unlock()
+
+	key := p.key(j.data)
+	if backlog, ok := p.keys[key]; ok {
+		p.keys[key] = append(backlog, j)
+		return true
+	}
+
+	p.keys[key] = nil
+	return false
+}
+
+// forget undoes the wait of j that found its key idle
+func (p *pool[T]) forget(j job[T]) {
+	p.keysMu.Lock()
+	defer p.keysMu.Unlock()
+	delete(p.keys, p.key(j.data))
+}
+
+// processKey runs j then the backlog of its key until it is empty
+func (p *pool[T]) processKey(j job[T]) {
+	key := p.key(j.data)
+	for {
+		_ = p.process(j.ctx, j.data, p.deliver)
+
+		p.keysMu.Lock()
+		backlog := p.keys[key]
+		if len(backlog) == 0 {
+			delete(p.keys, key)
+			p.keysMu.Unlock()
+			return
+		}
+
+		j = backlog[0]
+		var zero job[T]
+		backlog[0] = zero
+		p.keys[key] = backlog[1:]
+		p.keysMu.Unlock()
+	}
+}
@@ -0,0 +1,132 @@
+package channel_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/lamlv2305/rok/channel"
+)
+
+// runWorkers sends 40 values through a fanout whose middleware sleeps, and returns
+// how long it took until every value was delivered, in delivery order
+func runWorkers(t *testing.T, params ...channel.WithOptions[int]) (time.Duration, []int) {
+	t.Helper()
+
+	params = append(params, channel.WithMiddleware(func(v int) bool {
+		// later values finish first, so only the ordered mode keeps input order
+		time.Sleep(time.Duration(5-v%5) * time.Millisecond)
+		return true
+	}))
+	f := channel.NewFanout(params...)
+	sub := f.Subscribe(40, nil)
+	defer sub.Close()
+
+	start := time.Now()
+	for idx := range 40 {
+		f.Send(idx)
+	}
+
+	f.Stop()
+	elapsed := time.Since(start)
+
+	got := make([]int, 0, 40)
+	for range 40 {
+		got = append(got, <-sub.C())
+	}
+
+	return elapsed, got
+}
+
+func TestWorkers(t *testing.T) {
+	serial, _ := runWorkers(t, channel.WithWorkers[int](1))
+	unordered, got := runWorkers(t, channel.WithWorkers[int](8))
+	if unordered >= serial/2 {
+		t.Fatalf("8 unordered workers took %s, 1 worker took %s", unordered, serial)
+	}
+
+	if len(got) != 40 {
+		t.Fatalf("delivered %d values, want 40", len(got))
+	}
+
+	_, got = runWorkers(t, channel.WithWorkers[int](8), channel.WithOrdered[int](true))
+	for idx := range got {
+		if got[idx] != idx {
+			t.Fatalf("ordered workers delivered %v", got)
+		}
+	}
+}
+
+func TestKeyedOrder(t *testing.T) {
+	type event struct {
+		key string
+		seq int
+	}
+
+	f := channel.NewFanout(
+		channel.WithWorkers[event](4),
+		channel.WithKeyedOrder(func(e event) string { return e.key }),
+		channel.WithMiddleware(func(e event) bool {
+			// later values finish first, so a key running on two workers at once would deliver out of order
+			time.Sleep(time.Duration(5-e.seq%5) * time.Millisecond)
+			return true
+		}),
+	)
+	sub := f.Subscribe(40, nil)
+	defer sub.Close()
+
+	for idx := range 20 {
+		f.Send(event{key: "A", seq: idx})
+		f.Send(event{key: "B", seq: idx})
+	}
+
+	f.Stop()
+
+	next := map[string]int{}
+	for range 40 {
+		e := <-sub.C()
+		if e.seq != next[e.key] {
+			t.Fatalf("key %s delivered %d, want %d", e.key, e.seq, next[e.key])
+		}
+		next[e.key]++
+	}
+}
+
+func TestWorkersSendContext(t *testing.T) {
+	release := make(chan struct{})
+	started := make(chan struct{}, 1)
+	f := channel.NewFanout(
+		channel.WithWorkers[int](1),
+		channel.WithMiddleware(func(int) bool {
+			started <- struct{}{}
+			<-release
+			return true
+		}),
+	)
+
+	// the worker is busy with the first value and the queue holds the second
+	f.Send(1)
+	<-started
+	f.Send(2)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- f.SendContext(ctx, 3)
+	}()
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("SendContext returned %v, want context.Canceled", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("SendContext with a cancelled context blocked on busy workers")
+	}
+
+	close(release)
+	f.Stop()
+}